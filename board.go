@@ -12,6 +12,8 @@ type board struct {
 	size    int
 	places  [][]*place
 	koPlace *place
+	// version changes with every stone put or removed.
+	version int
 }
 
 func newBoard(size int) *board {
@@ -39,10 +41,10 @@ func (b *board) place(row int, column int) (*place, error) {
 	return b.places[row][column], nil
 }
 
-func (b *board) put(row int, column int, color Color) error {
+func (b *board) put(row int, column int, color Color) ([]*place, error) {
 	p, err := b.place(row, column)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	return p.put(color)
 }
@@ -56,3 +58,15 @@ func (b *board) hashSum() hashSum {
 	}
 	return hashSum(md5.Sum(bytes))
 }
+
+func (b *board) rows() []string {
+	rows := make([]string, b.size)
+	for r := 0; r < b.size; r++ {
+		bytes := make([]byte, b.size)
+		for c := 0; c < b.size; c++ {
+			bytes[c] = b.places[r][c].color.symbol()
+		}
+		rows[r] = string(bytes)
+	}
+	return rows
+}
@@ -104,6 +104,38 @@ var _ = Describe("Board", func() {
 			})
 		})
 	})
+	Describe("group", func() {
+		BeforeEach(func() {
+			b = newBoard(5)
+		})
+		put := func(color Color, places ...[2]int) {
+			for _, p := range places {
+				_, err := b.put(p[0], p[1], color)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+		Specify("loses liberties taken by enemy stones", func() {
+			put(Black, [2]int{2, 2})
+			Expect(b.places[2][2].group.liberties()).To(Equal(4))
+			put(White, [2]int{1, 2}, [2]int{2, 1})
+			Expect(b.places[2][2].group.liberties()).To(Equal(2))
+		})
+		Specify("joins stones into one group", func() {
+			put(Black, [2]int{0, 0}, [2]int{0, 2}, [2]int{0, 1})
+			g := b.places[0][1].group
+			Expect(b.places[0][0].group).To(BeIdenticalTo(g))
+			Expect(b.places[0][2].group).To(BeIdenticalTo(g))
+			Expect(g.liberties()).To(Equal(4))
+		})
+		Specify("is captured when the last liberty is taken", func() {
+			put(Black, [2]int{0, 0}, [2]int{0, 1})
+			put(White, [2]int{1, 0}, [2]int{1, 1})
+			captured, err := b.put(0, 2, White)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(captured).To(HaveLen(2))
+			Expect(b.places[1][0].group.liberties()).To(Equal(5))
+		})
+	})
 })
 
 func EqualToPlace(expected *place) types.GomegaMatcher {
@@ -0,0 +1,308 @@
+package ggo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/someanon/ggo/timer"
+)
+
+// SetClock sets clock the game clocks measure time with, real clock by
+// default. It is used to drive clocks by FakeClock, e.g. in tests and
+// simulations, and can't be changed once clocks are started.
+func (g *Game) SetClock(c timer.Clock) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.clocks != nil {
+		return errors.New("clocks are already started")
+	}
+	g.clock = c
+	return nil
+}
+
+// StartClocks creates clock of each player from the time system and
+// starts clock of the color to move. Clocks switch on moves and passes,
+// player whose time is over loses. Hourglass clocks are linked. Clocks
+// are not started by NewGame, so replayed and imported games don't
+// tick. Clocks of game restored from JSON continue from saved state,
+// running clock of adjourned game is paused.
+func (g *Game) StartClocks() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.parameters.TimeSystem == nil {
+		return errors.New("game has no time system")
+	}
+	if g.clocks != nil {
+		return errors.New("clocks are already started")
+	}
+	if g.phase == Over {
+		return errors.New("game is over")
+	}
+	clock := g.clock
+	if clock == nil {
+		clock = timer.RealClock
+	}
+	callbacks := func(color Color) timer.Callbacks {
+		return timer.Callbacks{
+			OnBaseOver:   func() { g.clockEvent(BaseTimeOver{Color: color}) },
+			OnPeriodOver: func() { g.periodOver(color) },
+			OnOver:       func() { g.timeOver(color) },
+		}
+	}
+	black, white, err := timer.NewAsymmetricTimerPair(*g.parameters.TimeSystemOf(Black), *g.parameters.TimeSystemOf(White),
+		callbacks(Black), callbacks(White), clock)
+	if err != nil {
+		return err
+	}
+	clocks := map[Color]*timer.Timer{Black: black, White: white}
+	for color, state := range g.savedClocks {
+		t, ok := clocks[color]
+		if !ok {
+			return fmt.Errorf("unknown clock color %v", color)
+		}
+		state.Running, state.Paused = false, false
+		if err := t.Restore(state); err != nil {
+			return fmt.Errorf("invalid %v clock state: %v", color, err)
+		}
+	}
+	g.clocks = clocks
+	g.switchClocks(g.moveColor)
+	if g.adjourned {
+		return g.clocks[g.running].Pause()
+	}
+	return nil
+}
+
+// Adjourn stops the game, e.g. overnight or for a dispute. Clocks are
+// frozen, running clock keeps time used for the current move. Moves are
+// rejected until Resume.
+func (g *Game) Adjourn() error {
+	g.mu.Lock()
+	err := g.adjourn()
+	g.mu.Unlock()
+	g.flush()
+	return err
+}
+
+func (g *Game) adjourn() error {
+	if g.phase == Over {
+		return errors.New("game is over")
+	}
+	if g.adjourned {
+		return errors.New("game is already adjourned")
+	}
+	if g.running != Empty {
+		if err := g.clocks[g.running].Pause(); err != nil {
+			return err
+		}
+	}
+	g.adjourned = true
+	g.pending = append(g.pending, Adjourned{})
+	return nil
+}
+
+// Resume continues adjourned game and its running clock.
+func (g *Game) Resume() error {
+	g.mu.Lock()
+	err := g.resume()
+	g.mu.Unlock()
+	g.flush()
+	return err
+}
+
+func (g *Game) resume() error {
+	if !g.adjourned {
+		return errors.New("game isn't adjourned")
+	}
+	if g.running != Empty {
+		if err := g.clocks[g.running].Resume(); err != nil {
+			return err
+		}
+	}
+	g.adjourned = false
+	g.pending = append(g.pending, Resumed{})
+	return nil
+}
+
+func (g *Game) Adjourned() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.adjourned
+}
+
+// RemainingTime returns main time left of color, or time of the current
+// byo-yomi period when main time is over, and byo-yomi periods left.
+func (g *Game) RemainingTime(color Color) (time.Duration, int) {
+	s, _ := g.ClockState(color)
+	if s.Base > 0 || s.Over {
+		return s.Base, s.Periods
+	}
+	return s.ByoYomi, s.Periods
+}
+
+// ClockState returns live state of the clock of color, or state of new
+// clock when clocks are not started. It reports false when the game has
+// no time system.
+func (g *Game) ClockState(color Color) (timer.State, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.parameters.TimeSystemOf(color) == nil {
+		return timer.State{}, false
+	}
+	return g.clockState(color), true
+}
+
+func (g *Game) clockState(color Color) timer.State {
+	if t, ok := g.clocks[color]; ok {
+		return t.State()
+	}
+	if s, ok := g.savedClocks[color]; ok {
+		return s
+	}
+	return timer.InitialState(*g.parameters.TimeSystemOf(color))
+}
+
+// clockStates returns states of started clocks.
+func (g *Game) clockStates() map[Color]timer.State {
+	if g.clocks == nil {
+		return g.savedClocks
+	}
+	states := make(map[Color]timer.State, len(g.clocks))
+	for color, t := range g.clocks {
+		states[color] = t.State()
+	}
+	return states
+}
+
+// ReportThinkTime sets think time of the next move or pass of color
+// measured by its client. Clock of color credits back network lag of
+// the move, up to lag allowance of the time system.
+func (g *Game) ReportThinkTime(color Color, think time.Duration) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.moveColor != color {
+		return errors.New("turn of another color")
+	}
+	if think < 0 {
+		return errors.New("think time should be greater or equal to zero")
+	}
+	g.think = &think
+	return nil
+}
+
+// LagCredits returns audit records of lag credited to color, which are
+// kept in saved clock state.
+func (g *Game) LagCredits(color Color) []timer.LagCredit {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if t, ok := g.clocks[color]; ok {
+		return t.LagCredits()
+	}
+	return g.savedClocks[color].LagCredits
+}
+
+// Result returns result of the game, which is known when the game is
+// over by time or the result is restored from JSON.
+func (g *Game) Result() (Result, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.result == nil {
+		return Result{}, false
+	}
+	return *g.result, true
+}
+
+// SetResult sets result of the game, e.g. read from imported record.
+// Game being played ends with the result.
+func (g *Game) SetResult(result Result) {
+	g.mu.Lock()
+	if g.phase == Over {
+		g.result = &result
+		g.info.Result = result.String()
+	} else {
+		g.finish(&result)
+	}
+	g.mu.Unlock()
+	g.flush()
+}
+
+// switchClocks stops running clock and starts clock of color, or no
+// clock when color is empty. Stopped clock records time of the last
+// move, since clock stops only after move of its color.
+func (g *Game) switchClocks(color Color) {
+	if g.clocks == nil {
+		return
+	}
+	if g.running != Empty {
+		t := g.clocks[g.running]
+		if g.think != nil {
+			t.SwitchWithThinkTime(*g.think)
+		} else {
+			t.Switch()
+		}
+		if n := len(g.history); n > 0 && g.history[n-1].Color == g.running {
+			g.history[n-1].Time = moveTime(t, g.parameters.TimeSystemOf(g.running))
+		}
+		g.running = Empty
+	}
+	g.think = nil
+	if color != Empty {
+		g.clocks[color].Switch()
+		g.running = color
+	}
+}
+
+// finish ends the game with result, which is nil when it is not known.
+func (g *Game) finish(result *Result) {
+	g.switchClocks(Empty)
+	g.phase = Over
+	g.result = result
+	if result != nil {
+		g.info.Result = result.String()
+	}
+	g.pending = append(g.pending, PhaseChanged{Phase: Over}, GameOver{Result: g.info.Result})
+}
+
+func moveTime(t *timer.Timer, p *timer.Parameters) *MoveTime {
+	s := t.State()
+	mt := &MoveTime{Elapsed: t.LastMoveTime(), Left: s.Base}
+	if s.Base == 0 && !s.Over {
+		mt.Left = s.ByoYomi
+		if p.System == timer.Canadian || p.Moves > 1 {
+			mt.Overtime = s.Moves
+		} else {
+			mt.Overtime = s.Periods
+		}
+	}
+	return mt
+}
+
+func (g *Game) clockEvent(e Event) {
+	g.mu.Lock()
+	g.pending = append(g.pending, e)
+	g.mu.Unlock()
+	g.flush()
+}
+
+func (g *Game) periodOver(color Color) {
+	g.mu.Lock()
+	_, periods := g.clocks[color].Remaining()
+	g.pending = append(g.pending, PeriodOver{Color: color, Periods: periods})
+	g.mu.Unlock()
+	g.flush()
+}
+
+func (g *Game) timeOver(color Color) {
+	g.mu.Lock()
+	if g.phase != Over {
+		// Clock of color is over already, so it doesn't run any more.
+		if g.running == color {
+			g.running = Empty
+		}
+		g.pending = append(g.pending, TimeOver{Color: color})
+		g.finish(&Result{Winner: g.nextColor(color), Reason: ByTime})
+	}
+	g.mu.Unlock()
+	g.flush()
+}
@@ -0,0 +1,357 @@
+package ggo
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Clocks", func() {
+	var (
+		g     *Game
+		clock *timer.FakeClock
+	)
+	BeforeEach(func() {
+		g = NewGame(Parameters{BoardSize: 5, TimeSystem: &timer.Parameters{Base: 1 * time.Second}})
+		clock = timer.NewFakeClock(time.Unix(0, 0))
+		Expect(g.SetClock(clock)).To(Succeed())
+	})
+	It("don't start without time system", func() {
+		Expect(NewGame(Parameters{BoardSize: 5}).StartClocks()).ToNot(Succeed())
+		Expect(g.StartClocks()).To(Succeed())
+		Expect(g.StartClocks()).ToNot(Succeed())
+	})
+	It("use clock set before start", func() {
+		Expect(g.StartClocks()).To(Succeed())
+		Expect(g.SetClock(timer.RealClock)).ToNot(Succeed())
+		clock.Advance(time.Second)
+		Expect(g.Phase()).To(Equal(Over))
+	})
+	It("show full time before start", func() {
+		remaining, periods := g.RemainingTime(White)
+		Expect(remaining).To(Equal(time.Second))
+		Expect(periods).To(Equal(0))
+	})
+	It("switch on moves", func() {
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(100 * time.Millisecond)
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		clock.Advance(200 * time.Millisecond)
+		Expect(g.Pass(White)).To(Succeed())
+		Expect(g.Pass(Black)).To(Succeed())
+		black, _ := g.RemainingTime(Black)
+		Expect(black).To(Equal(900 * time.Millisecond))
+		white, _ := g.RemainingTime(White)
+		Expect(white).To(Equal(800 * time.Millisecond))
+		clock.Advance(time.Second)
+		_, known := g.Result()
+		Expect(known).To(BeFalse())
+	})
+	It("end the game when time is over", func() {
+		var events []Event
+		g.Subscribe(ObserverFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(999 * time.Millisecond)
+		Expect(g.Phase()).To(Equal(Playing))
+		clock.Advance(time.Millisecond)
+		Expect(g.Phase()).To(Equal(Over))
+		result, known := g.Result()
+		Expect(known).To(BeTrue())
+		Expect(result).To(Equal(Result{Winner: White, Reason: ByTime}))
+		Expect(g.Info().Result).To(Equal("W+T"))
+		Expect(g.Move(2, 2, Black)).ToNot(Succeed())
+		Expect(events).To(Equal([]Event{
+			TimeOver{Color: Black},
+			PhaseChanged{Phase: Over},
+			GameOver{Result: "W+T"},
+		}))
+	})
+	It("link hourglass clocks", func() {
+		g = NewGame(Parameters{BoardSize: 5, TimeSystem: &timer.Parameters{System: timer.Hourglass, Base: 10 * time.Second}})
+		Expect(g.SetClock(clock)).To(Succeed())
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(4 * time.Second)
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		black, _ := g.RemainingTime(Black)
+		white, _ := g.RemainingTime(White)
+		Expect(black).To(Equal(6 * time.Second))
+		Expect(white).To(Equal(14 * time.Second))
+		clock.Advance(14 * time.Second)
+		result, _ := g.Result()
+		Expect(result).To(Equal(Result{Winner: Black, Reason: ByTime}))
+	})
+	It("continue from state saved in JSON", func() {
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(300 * time.Millisecond)
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		clock.Advance(100 * time.Millisecond)
+		state, ok := g.ClockState(White)
+		Expect(ok).To(BeTrue())
+		Expect(state.Base).To(Equal(900 * time.Millisecond))
+		Expect(state.Running).To(BeTrue())
+		data, err := json.Marshal(g)
+		Expect(err).ToNot(HaveOccurred())
+
+		restored := &Game{}
+		Expect(json.Unmarshal(data, restored)).To(Succeed())
+		Expect(restored.SetClock(clock)).To(Succeed())
+		black, _ := restored.RemainingTime(Black)
+		Expect(black).To(Equal(700 * time.Millisecond))
+		Expect(restored.StartClocks()).To(Succeed())
+		clock.Advance(899 * time.Millisecond)
+		Expect(restored.Phase()).To(Equal(Playing))
+		clock.Advance(time.Millisecond)
+		result, _ := restored.Result()
+		Expect(result).To(Equal(Result{Winner: Black, Reason: ByTime}))
+	})
+	It("restore time loss from JSON", func() {
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(time.Second)
+		data, err := json.Marshal(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored := &Game{}
+		Expect(json.Unmarshal(data, restored)).To(Succeed())
+		Expect(restored.Phase()).To(Equal(Over))
+		result, _ := restored.Result()
+		Expect(result).To(Equal(Result{Winner: Black, Reason: ByTime}))
+	})
+	It("give each color its own time", func() {
+		g = NewGame(Parameters{
+			BoardSize:       5,
+			TimeSystem:      &timer.Parameters{Base: 1 * time.Second},
+			WhiteTimeSystem: &timer.Parameters{ByoYomi: 2 * time.Second, Periods: 1, Moves: 1},
+		})
+		Expect(g.SetClock(clock)).To(Succeed())
+		white, periods := g.RemainingTime(White)
+		Expect(white).To(Equal(2 * time.Second))
+		Expect(periods).To(Equal(1))
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(500 * time.Millisecond)
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		clock.Advance(1500 * time.Millisecond)
+		Expect(g.Pass(White)).To(Succeed())
+		black, _ := g.RemainingTime(Black)
+		Expect(black).To(Equal(500 * time.Millisecond))
+		white, _ = g.RemainingTime(White)
+		Expect(white).To(Equal(2 * time.Second))
+
+		data, err := json.Marshal(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored := &Game{}
+		Expect(json.Unmarshal(data, restored)).To(Succeed())
+		Expect(restored.Parameters()).To(Equal(g.Parameters()))
+	})
+	It("validate time systems of both colors", func() {
+		for _, p := range []Parameters{
+			{BoardSize: 0},
+			{BoardSize: 5, WhiteTimeSystem: &timer.Parameters{Base: 1 * time.Second}},
+			{BoardSize: 5, TimeSystem: &timer.Parameters{Base: 1 * time.Second}, WhiteTimeSystem: &timer.Parameters{}},
+			{BoardSize: 5, TimeSystem: &timer.Parameters{Base: 1 * time.Second},
+				WhiteTimeSystem: &timer.Parameters{System: timer.Hourglass, Base: 1 * time.Second}},
+		} {
+			Expect(p.Validate()).ToNot(Succeed(), "%+v", p)
+		}
+		Expect(g.Parameters().Validate()).To(Succeed())
+	})
+	It("record time of moves", func() {
+		g = NewGame(Parameters{BoardSize: 5, TimeSystem: &timer.Parameters{
+			Base: time.Second, ByoYomi: 10 * time.Second, Periods: 3, Moves: 1,
+		}})
+		Expect(g.SetClock(clock)).To(Succeed())
+		var placed []Move
+		g.Subscribe(ObserverFunc(func(e Event) {
+			if e, ok := e.(StonePlaced); ok {
+				placed = append(placed, e.Move)
+			}
+		}))
+		Expect(g.Move(0, 0, Black)).To(Succeed())
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(300 * time.Millisecond)
+		Expect(g.Move(1, 1, White)).To(Succeed())
+		clock.Advance(3 * time.Second)
+		Expect(g.Adjourn()).To(Succeed())
+		clock.Advance(time.Hour)
+		Expect(g.Resume()).To(Succeed())
+		clock.Advance(time.Second)
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		Expect(g.Pass(White)).To(Succeed())
+		moves := g.Moves()
+		Expect(moves[0].Time).To(BeNil())
+		Expect(moves[1].Time).To(Equal(&MoveTime{Elapsed: 300 * time.Millisecond, Left: 700 * time.Millisecond}))
+		Expect(moves[2].Time).To(Equal(&MoveTime{Elapsed: 4 * time.Second, Left: 10 * time.Second, Overtime: 3}))
+		Expect(moves[3].Time).To(Equal(&MoveTime{Left: 700 * time.Millisecond}))
+		Expect(placed[2].Time).To(Equal(moves[2].Time))
+
+		data, err := json.Marshal(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored := &Game{}
+		Expect(json.Unmarshal(data, restored)).To(Succeed())
+		Expect(restored.Moves()).To(Equal(moves))
+		Expect(restored.SetMoveTime(5, nil)).ToNot(Succeed())
+	})
+	It("tell about events one at a time", func() {
+		g = NewGame(Parameters{BoardSize: 5, TimeSystem: &timer.Parameters{
+			ByoYomi: time.Millisecond, Periods: 1000, Moves: 1,
+		}})
+		Expect(g.SetClock(clock)).To(Succeed())
+		var inside, overlaps int32
+		g.Subscribe(ObserverFunc(func(e Event) {
+			if atomic.AddInt32(&inside, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			time.Sleep(10 * time.Microsecond)
+			atomic.AddInt32(&inside, -1)
+		}))
+		Expect(g.StartClocks()).To(Succeed())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 200; i++ {
+				clock.Advance(time.Millisecond)
+			}
+		}()
+		for i := 0; i < 200 && g.Phase() == Playing; i++ {
+			g.Pass(g.MoveColor())
+			g.Move(i%5, i/5%5, g.MoveColor())
+		}
+		<-done
+		Expect(atomic.LoadInt32(&overlaps)).To(BeZero())
+	})
+	It("keep running game from being restored into", func() {
+		data, err := json.Marshal(NewGame(Parameters{BoardSize: 5, TimeSystem: &timer.Parameters{Base: time.Second}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.StartClocks()).To(Succeed())
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		Expect(json.Unmarshal(data, g)).ToNot(Succeed())
+		Expect(g.Moves()).To(HaveLen(1))
+		clock.Advance(time.Second)
+		result, _ := g.Result()
+		Expect(result).To(Equal(Result{Winner: Black, Reason: ByTime}))
+	})
+	It("write time of moves in seconds", func() {
+		t := MoveTime{Elapsed: 1500 * time.Millisecond, Left: 30 * time.Second, Overtime: 3}
+		data, err := json.Marshal(t)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"elapsed":"1.5s","left":30,"overtime":3}`))
+		var restored MoveTime
+		Expect(json.Unmarshal(data, &restored)).To(Succeed())
+		Expect(restored).To(Equal(t))
+		Expect(json.Unmarshal([]byte(`{"left":"soon"}`), &restored)).ToNot(Succeed())
+	})
+	It("freeze when the game is adjourned", func() {
+		var events []Event
+		g.Subscribe(ObserverFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(300 * time.Millisecond)
+		Expect(g.Adjourn()).To(Succeed())
+		Expect(g.Adjourn()).ToNot(Succeed())
+		clock.Advance(time.Hour)
+		Expect(g.Phase()).To(Equal(Playing))
+		Expect(g.Move(2, 2, Black)).ToNot(Succeed())
+		black, _ := g.RemainingTime(Black)
+		Expect(black).To(Equal(700 * time.Millisecond))
+
+		data, err := json.Marshal(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored := &Game{}
+		Expect(json.Unmarshal(data, restored)).To(Succeed())
+		Expect(restored.SetClock(clock)).To(Succeed())
+		Expect(restored.Adjourned()).To(BeTrue())
+		Expect(restored.StartClocks()).To(Succeed())
+		clock.Advance(time.Hour)
+		Expect(restored.Phase()).To(Equal(Playing))
+		Expect(restored.Resume()).To(Succeed())
+		clock.Advance(699 * time.Millisecond)
+		Expect(restored.Phase()).To(Equal(Playing))
+		clock.Advance(time.Millisecond)
+		Expect(restored.Phase()).To(Equal(Over))
+
+		Expect(g.Resume()).To(Succeed())
+		Expect(g.Resume()).ToNot(Succeed())
+		clock.Advance(100 * time.Millisecond)
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		black, _ = g.RemainingTime(Black)
+		Expect(black).To(Equal(600 * time.Millisecond))
+		Expect(events[:2]).To(Equal([]Event{Adjourned{}, Resumed{}}))
+	})
+	It("credit lag of reported think time", func() {
+		g = NewGame(Parameters{BoardSize: 5, TimeSystem: &timer.Parameters{
+			Base: 10 * time.Second, Lag: &timer.Lag{PerMove: time.Second},
+		}})
+		Expect(g.SetClock(clock)).To(Succeed())
+		Expect(g.StartClocks()).To(Succeed())
+		clock.Advance(3 * time.Second)
+		Expect(g.ReportThinkTime(White, time.Second)).ToNot(Succeed())
+		Expect(g.ReportThinkTime(Black, 2500*time.Millisecond)).To(Succeed())
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		black, _ := g.RemainingTime(Black)
+		Expect(black).To(Equal(7500 * time.Millisecond))
+		clock.Advance(3 * time.Second)
+		Expect(g.Pass(White)).To(Succeed())
+		white, _ := g.RemainingTime(White)
+		Expect(white).To(Equal(7 * time.Second))
+		Expect(g.LagCredits(Black)).To(Equal([]timer.LagCredit{
+			{Move: 1, Elapsed: 3 * time.Second, Think: 2500 * time.Millisecond, Credited: 500 * time.Millisecond},
+		}))
+		Expect(g.LagCredits(White)).To(BeEmpty())
+
+		data, err := json.Marshal(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored := &Game{}
+		Expect(json.Unmarshal(data, restored)).To(Succeed())
+		Expect(restored.LagCredits(Black)).To(Equal(g.LagCredits(Black)))
+		Expect(restored.SetClock(clock)).To(Succeed())
+		Expect(restored.StartClocks()).To(Succeed())
+		Expect(restored.LagCredits(Black)).To(Equal(g.LagCredits(Black)))
+		Expect(restored.ReportThinkTime(Black, 0)).To(Succeed())
+		clock.Advance(500 * time.Millisecond)
+		Expect(restored.Move(3, 3, Black)).To(Succeed())
+		Expect(restored.LagCredits(Black)).To(HaveLen(2))
+		Expect(restored.LagCredits(Black)[1]).To(Equal(
+			timer.LagCredit{Move: 2, Elapsed: 500 * time.Millisecond, Credited: 500 * time.Millisecond}))
+	})
+})
+
+var _ = Describe("Result", func() {
+	It("is written in SGF form", func() {
+		Expect(Result{Winner: Black, Score: 3.5}.String()).To(Equal("B+3.5"))
+		Expect(Result{Winner: White, Reason: ByResignation}.String()).To(Equal("W+R"))
+		Expect(Result{Winner: Black, Reason: ByTime}.String()).To(Equal("B+T"))
+		Expect(Result{}.String()).To(Equal("0"))
+	})
+	It("is read from SGF form", func() {
+		for s, r := range map[string]Result{
+			"B+3.5":    {Winner: Black, Score: 3.5},
+			"W+Resign": {Winner: White, Reason: ByResignation},
+			"w+t":      {Winner: White, Reason: ByTime},
+			"B+F":      {Winner: Black, Reason: ByForfeit},
+			"Draw":     {},
+		} {
+			Expect(ParseResult(s)).To(Equal(r))
+		}
+		for _, s := range []string{"", "B", "X+R", "B+?"} {
+			_, err := ParseResult(s)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+	It("ends game when set", func() {
+		g := NewGame(Parameters{BoardSize: 5})
+		Expect(g.Move(2, 2, Black)).To(Succeed())
+		g.SetResult(Result{Winner: Black, Reason: ByResignation})
+		Expect(g.Phase()).To(Equal(Over))
+		Expect(g.Info().Result).To(Equal("B+R"))
+		Expect(g.Pass(White)).ToNot(Succeed())
+		result, known := g.Result()
+		Expect(known).To(BeTrue())
+		Expect(result).To(Equal(Result{Winner: Black, Reason: ByResignation}))
+	})
+})
@@ -1,5 +1,9 @@
 package ggo
 
+import (
+	"fmt"
+)
+
 type Color byte
 
 const (
@@ -7,3 +11,46 @@ const (
 	Black
 	White
 )
+
+func (c Color) String() string {
+	switch c {
+	case Empty:
+		return "empty"
+	case Black:
+		return "black"
+	case White:
+		return "white"
+	}
+	return fmt.Sprintf("Color(%d)", byte(c))
+}
+
+func (c Color) MarshalText() ([]byte, error) {
+	if c > White {
+		return nil, fmt.Errorf("unknown color %d", byte(c))
+	}
+	return []byte(c.String()), nil
+}
+
+func (c *Color) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "empty":
+		*c = Empty
+	case "black":
+		*c = Black
+	case "white":
+		*c = White
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+func (c Color) symbol() byte {
+	switch c {
+	case Black:
+		return 'X'
+	case White:
+		return 'O'
+	}
+	return '.'
+}
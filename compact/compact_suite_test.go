@@ -0,0 +1,13 @@
+package compact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Compact Suite")
+}
@@ -0,0 +1,176 @@
+package compact_test
+
+import (
+	"bytes"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/compact"
+	"github.com/someanon/ggo/sgf"
+	"github.com/someanon/ggo/timer"
+)
+
+// randomGame plays count random legal moves, passing when random place
+// is not found quickly.
+func randomGame(size int, count int, seed int64) *ggo.Game {
+	rnd := rand.New(rand.NewSource(seed))
+	g := ggo.NewGame(ggo.Parameters{BoardSize: size, Komi: 6.5})
+	for i := 0; i < count && g.Phase() == ggo.Playing; i++ {
+		played := false
+		for try := 0; try < 20 && !played; try++ {
+			played = g.Move(rnd.Intn(size), rnd.Intn(size), g.MoveColor()) == nil
+		}
+		if !played {
+			g.Pass(g.MoveColor())
+		}
+	}
+	return g
+}
+
+var _ = Describe("Compact", func() {
+	It("keeps parameters, info, setup and moves", func() {
+		g := ggo.NewGame(ggo.Parameters{
+			BoardSize:  9,
+			Komi:       -2.75,
+			TimeSystem: &timer.Parameters{Base: 600 * time.Second, ByoYomi: 30 * time.Second, Periods: 5, Moves: 1},
+		})
+		g.SetInfo(ggo.Info{BlackName: "black", WhiteName: "white", Result: "W+R"})
+		Expect(g.Setup(2, 2, ggo.Black)).To(Succeed())
+		Expect(g.Setup(6, 6, ggo.White)).To(Succeed())
+		Expect(g.SetMoveColor(ggo.White)).To(Succeed())
+		Expect(g.Move(4, 4, ggo.White)).To(Succeed())
+		Expect(g.Pass(ggo.Black)).To(Succeed())
+		Expect(g.Move(0, 8, ggo.White)).To(Succeed())
+
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := Decode(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Parameters()).To(Equal(g.Parameters()))
+		Expect(restored.Info()).To(Equal(g.Info()))
+		Expect(restored.SetupStones()).To(Equal(g.SetupStones()))
+		Expect(restored.Moves()).To(Equal(g.Moves()))
+		Expect(restored.String()).To(Equal(g.String()))
+		Expect(restored.Phase()).To(Equal(ggo.Over))
+	})
+	It("keeps first move color of a game without moves", func() {
+		g := ggo.NewGame(ggo.Parameters{BoardSize: 19})
+		Expect(g.SetupHandicap(4)).To(Succeed())
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := Decode(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.MoveColor()).To(Equal(ggo.White))
+		Expect(restored.SetupStones()).To(HaveLen(4))
+	})
+	It("keeps time odds", func() {
+		g := ggo.NewGame(ggo.Parameters{
+			BoardSize:       9,
+			TimeSystem:      &timer.Parameters{Base: 600 * time.Second, ByoYomi: 30 * time.Second, Periods: 5, Moves: 1},
+			WhiteTimeSystem: &timer.Parameters{Base: 300 * time.Second},
+		})
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := Decode(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Parameters()).To(Equal(g.Parameters()))
+	})
+	It("decodes version 1", func() {
+		g := randomGame(9, 40, 3)
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		// Version 1 lacks empty white time system, which follows magic,
+		// version, size, komi 6.5 and empty time system.
+		Expect(data[:9]).To(Equal([]byte("GGO\x03\x09\x94\x0a\x00\x00")))
+		// Nor move times, which are the last zero byte.
+		Expect(data[len(data)-1]).To(Equal(byte(0)))
+		v1 := append([]byte("GGO\x01"), data[4:8]...)
+		v1 = append(v1, data[9:len(data)-1]...)
+		restored, err := Decode(v1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Parameters()).To(Equal(g.Parameters()))
+		Expect(restored.Moves()).To(Equal(g.Moves()))
+	})
+	It("keeps move times", func() {
+		g := ggo.NewGame(ggo.Parameters{BoardSize: 9, TimeSystem: &timer.Parameters{
+			Base: 60 * time.Second, ByoYomi: 30 * time.Second, Periods: 5, Moves: 1,
+		}})
+		Expect(g.Move(4, 4, ggo.Black)).To(Succeed())
+		Expect(g.Move(2, 2, ggo.White)).To(Succeed())
+		Expect(g.Pass(ggo.Black)).To(Succeed())
+		Expect(g.SetMoveTime(1, &ggo.MoveTime{Elapsed: 2500 * time.Millisecond, Left: 57500 * time.Millisecond})).
+			To(Succeed())
+		Expect(g.SetMoveTime(3, &ggo.MoveTime{Left: 12 * time.Second, Overtime: 4})).To(Succeed())
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := Decode(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Moves()).To(Equal(g.Moves()))
+
+		// Version 2 has no move times.
+		v2 := append([]byte("GGO\x02"), data[4:]...)
+		restored, err = Decode(v2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Moves()).To(HaveLen(3))
+		Expect(restored.Moves()[0].Time).To(BeNil())
+	})
+	It("keeps game over on time", func() {
+		clock := timer.NewFakeClock(time.Unix(0, 0))
+		g := ggo.NewGame(ggo.Parameters{BoardSize: 9, TimeSystem: &timer.Parameters{Base: time.Minute}})
+		Expect(g.SetClock(clock)).To(Succeed())
+		Expect(g.StartClocks()).To(Succeed())
+		Expect(g.Move(4, 4, ggo.Black)).To(Succeed())
+		clock.Advance(time.Minute)
+		Expect(g.Phase()).To(Equal(ggo.Over))
+
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := Decode(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Phase()).To(Equal(ggo.Over))
+		result, known := restored.Result()
+		Expect(known).To(BeTrue())
+		Expect(result).To(Equal(ggo.Result{Winner: ggo.Black, Reason: ggo.ByTime}))
+		Expect(restored.Info()).To(Equal(g.Info()))
+		Expect(restored.Move(2, 2, ggo.White)).ToNot(Succeed())
+	})
+	It("rejects komi with more than two decimals", func() {
+		_, err := Encode(ggo.NewGame(ggo.Parameters{BoardSize: 9, Komi: 0.125}))
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects invalid data", func() {
+		data, err := Encode(randomGame(9, 40, 1))
+		Expect(err).ToNot(HaveOccurred())
+		for _, d := range [][]byte{
+			nil,
+			[]byte("SGF"),
+			append([]byte("GGO\x09"), data[4:]...),
+			data[:len(data)-3],
+		} {
+			_, err := Decode(d)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+	It("rejects invalid time system", func() {
+		ts := `{"base":600,"byoYomi":30,"periods":5,"moves":0}`
+		data := append([]byte("GGO\x01\x09\x00"), byte(len(ts)))
+		data = append(data, ts...)
+		data = append(data, make([]byte, 8)...)
+		data = append(data, byte(ggo.Black), 0, 0, 0)
+		_, err := Decode(data)
+		Expect(err).To(MatchError(ContainSubstring("moves")))
+	})
+	It("is much smaller than SGF", func() {
+		g := randomGame(19, 250, 2)
+		data, err := Encode(g)
+		Expect(err).ToNot(HaveOccurred())
+		root, err := sgf.FromGame(g)
+		Expect(err).ToNot(HaveOccurred())
+		var buf bytes.Buffer
+		Expect(sgf.Write(&buf, root)).To(Succeed())
+		Expect(len(data) * 5).To(BeNumerically("<", buf.Len()))
+	})
+})
@@ -0,0 +1,53 @@
+package compact_test
+
+import (
+	"testing"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/compact"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(int64(1), 9, 60)
+	f.Add(int64(2), 19, 250)
+	f.Add(int64(3), 2, 10)
+	f.Fuzz(func(t *testing.T, seed int64, size int, count int) {
+		if size < 1 || size > 25 || count < 0 || count > 400 {
+			t.Skip()
+		}
+		g := randomGame(size, count, seed)
+		data, err := compact.Encode(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restored, err := compact.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.String() != g.String() || len(restored.Moves()) != len(g.Moves()) {
+			t.Fatalf("game differs after round trip:\n%v\n%v", g, restored)
+		}
+	})
+}
+
+func FuzzDecode(f *testing.F) {
+	seed, _ := compact.Encode(ggo.NewGame(ggo.Parameters{BoardSize: 9}))
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, err := compact.Decode(data)
+		if err != nil {
+			return
+		}
+		again, err := compact.Encode(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restored, err := compact.Decode(again)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.String() != g.String() {
+			t.Fatalf("game differs after round trip:\n%v\n%v", g, restored)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package coord
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Notation is a way to write board place. Row and column used by ggo are
+// zero based and counted from the top left corner.
+type Notation int
+
+const (
+	// GTP is column letter without I and row number from the bottom, e.g.
+	// "Q16". Pass is "pass".
+	GTP Notation = iota
+	// SGF is column and row letters from the top left corner, e.g. "pd".
+	// Pass is empty string, or "tt" on boards up to 19x19.
+	SGF
+	// Matrix is zero based row and column numbers from the top left
+	// corner, e.g. "3,15".
+	Matrix
+	// Japanese is column number from the right and row number from the
+	// top, e.g. "4-4" for the upper right star point.
+	Japanese
+)
+
+const (
+	gtpLetters = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+	sgfLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+const (
+	MaxGTPSize = len(gtpLetters)
+	MaxSGFSize = len(sgfLetters)
+)
+
+func (n Notation) String() string {
+	switch n {
+	case GTP:
+		return "GTP"
+	case SGF:
+		return "SGF"
+	case Matrix:
+		return "matrix"
+	case Japanese:
+		return "Japanese"
+	}
+	return fmt.Sprintf("Notation(%d)", int(n))
+}
+
+// GTPColumn returns column letter, or empty string when column can't be
+// written in GTP notation.
+func GTPColumn(column int) string {
+	if column < 0 || column >= len(gtpLetters) {
+		return ""
+	}
+	return gtpLetters[column : column+1]
+}
+
+// Format writes place of the board of size in notation.
+func Format(row int, column int, size int, n Notation) (string, error) {
+	if row < 0 || column < 0 || row >= size || column >= size {
+		return "", fmt.Errorf("place not found at row=%d, column=%d", row, column)
+	}
+	switch n {
+	case GTP:
+		if size > MaxGTPSize {
+			return "", errors.New("too large board for GTP notation")
+		}
+		return GTPColumn(column) + strconv.Itoa(size-row), nil
+	case SGF:
+		if size > MaxSGFSize {
+			return "", errors.New("too large board for SGF notation")
+		}
+		return string([]byte{sgfLetters[column], sgfLetters[row]}), nil
+	case Matrix:
+		return strconv.Itoa(row) + "," + strconv.Itoa(column), nil
+	case Japanese:
+		return strconv.Itoa(size-column) + "-" + strconv.Itoa(row+1), nil
+	}
+	return "", fmt.Errorf("unknown notation %v", n)
+}
+
+// FormatPass writes pass in notation.
+func FormatPass(n Notation) (string, error) {
+	switch n {
+	case GTP:
+		return "pass", nil
+	case SGF:
+		return "", nil
+	}
+	return "", fmt.Errorf("%v notation has no pass", n)
+}
+
+// IsPass reports whether s is pass in notation for the board of size.
+func IsPass(s string, size int, n Notation) bool {
+	switch n {
+	case GTP:
+		return strings.EqualFold(s, "pass")
+	case SGF:
+		return s == "" || s == "tt" && size <= 19
+	}
+	return false
+}
+
+// Parse reads place of the board of size written in notation.
+func Parse(s string, size int, n Notation) (row int, column int, err error) {
+	switch n {
+	case GTP:
+		row, column, err = parseGTP(s, size)
+	case SGF:
+		row, column, err = parseSGF(s, size)
+	case Matrix:
+		row, column, err = parseNumbers(s, ",")
+	case Japanese:
+		column, row, err = parseNumbers(s, "-")
+		column, row = size-column, row-1
+	default:
+		return 0, 0, fmt.Errorf("unknown notation %v", n)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid %v place %q: %v", n, s, err)
+	}
+	if row < 0 || column < 0 || row >= size || column >= size {
+		return 0, 0, fmt.Errorf("%v place %q is out of board", n, s)
+	}
+	return row, column, nil
+}
+
+// Detect guesses notation of s. Notations are told apart by their
+// shape, so detection is unambiguous for valid places.
+func Detect(s string) (Notation, error) {
+	switch {
+	case strings.EqualFold(s, "pass"):
+		return GTP, nil
+	case s == "":
+		return SGF, nil
+	case strings.Contains(s, ","):
+		return Matrix, nil
+	case strings.Contains(s, "-"):
+		return Japanese, nil
+	case len(s) == 2 && isLetter(s[0]) && isLetter(s[1]):
+		return SGF, nil
+	case len(s) >= 2 && isLetter(s[0]) && isDigit(s[1]):
+		return GTP, nil
+	}
+	return 0, fmt.Errorf("unknown notation of %q", s)
+}
+
+func parseGTP(s string, size int) (int, int, error) {
+	if size > MaxGTPSize {
+		return 0, 0, errors.New("too large board for GTP notation")
+	}
+	if len(s) < 2 {
+		return 0, 0, errors.New("too short")
+	}
+	column := strings.IndexByte(gtpLetters, strings.ToUpper(s[:1])[0])
+	if column < 0 {
+		return 0, 0, errors.New("unknown column letter")
+	}
+	number, err := strconv.Atoi(s[1:])
+	if err != nil {
+		return 0, 0, errors.New("row should be number")
+	}
+	return size - number, column, nil
+}
+
+func parseSGF(s string, size int) (int, int, error) {
+	if size > MaxSGFSize {
+		return 0, 0, errors.New("too large board for SGF notation")
+	}
+	if len(s) != 2 {
+		return 0, 0, errors.New("should be two letters")
+	}
+	column := strings.IndexByte(sgfLetters, s[0])
+	row := strings.IndexByte(sgfLetters, s[1])
+	if column < 0 || row < 0 {
+		return 0, 0, errors.New("unknown letter")
+	}
+	return row, column, nil
+}
+
+func parseNumbers(s string, separator string) (int, int, error) {
+	parts := strings.Split(s, separator)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("should be two numbers separated by %q", separator)
+	}
+	first, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	second, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, second, nil
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
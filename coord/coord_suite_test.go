@@ -0,0 +1,13 @@
+package coord_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCoord(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Coord Suite")
+}
@@ -0,0 +1,95 @@
+package coord_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/coord"
+)
+
+var _ = Describe("Coord", func() {
+	Describe("formatting", func() {
+		It("writes places of 19x19 board", func() {
+			Expect(Format(3, 15, 19, GTP)).To(Equal("Q16"))
+			Expect(Format(3, 15, 19, SGF)).To(Equal("pd"))
+			Expect(Format(3, 15, 19, Matrix)).To(Equal("3,15"))
+			Expect(Format(3, 15, 19, Japanese)).To(Equal("4-4"))
+			Expect(Format(18, 8, 19, GTP)).To(Equal("J1"))
+			Expect(Format(0, 0, 19, SGF)).To(Equal("aa"))
+		})
+		It("rejects places out of board", func() {
+			_, err := Format(19, 0, 19, GTP)
+			Expect(err).To(HaveOccurred())
+			_, err = Format(0, -1, 19, SGF)
+			Expect(err).To(HaveOccurred())
+		})
+		It("rejects too large board for GTP", func() {
+			_, err := Format(0, 0, 26, GTP)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("parsing", func() {
+		It("reads places of 19x19 board", func() {
+			check := func(s string, n Notation) {
+				row, column, err := Parse(s, 19, n)
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]int{row, column}).To(Equal([]int{3, 15}))
+			}
+			check("Q16", GTP)
+			check("q16", GTP)
+			check("pd", SGF)
+			check("3,15", Matrix)
+			check("4-4", Japanese)
+		})
+		It("rejects invalid places", func() {
+			for _, s := range []string{"I5", "A20", "A0", "Q", "Qx"} {
+				_, _, err := Parse(s, 19, GTP)
+				Expect(err).To(HaveOccurred(), s)
+			}
+			for _, s := range []string{"a", "ab1", "tz", "a1"} {
+				_, _, err := Parse(s, 19, SGF)
+				Expect(err).To(HaveOccurred(), s)
+			}
+			_, _, err := Parse("19,0", 19, Matrix)
+			Expect(err).To(HaveOccurred())
+			_, _, err = Parse("0-1", 19, Japanese)
+			Expect(err).To(HaveOccurred())
+		})
+		It("reads pass", func() {
+			Expect(IsPass("pass", 19, GTP)).To(BeTrue())
+			Expect(IsPass("PASS", 19, GTP)).To(BeTrue())
+			Expect(IsPass("", 19, SGF)).To(BeTrue())
+			Expect(IsPass("tt", 19, SGF)).To(BeTrue())
+			Expect(IsPass("tt", 21, SGF)).To(BeFalse())
+			Expect(IsPass("A1", 19, GTP)).To(BeFalse())
+		})
+	})
+	Describe("round trip", func() {
+		It("keeps every place of every board size", func() {
+			for _, n := range []Notation{GTP, SGF, Matrix, Japanese} {
+				for size := 1; size <= MaxGTPSize; size++ {
+					for row := 0; row < size; row++ {
+						for column := 0; column < size; column++ {
+							s, err := Format(row, column, size, n)
+							Expect(err).ToNot(HaveOccurred())
+							detected, err := Detect(s)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(detected).To(Equal(n))
+							r, c, err := Parse(s, size, n)
+							Expect(err).ToNot(HaveOccurred())
+							Expect([]int{r, c}).To(Equal([]int{row, column}), "%v %q", n, s)
+						}
+					}
+				}
+			}
+		})
+		It("keeps places of large boards in SGF", func() {
+			for row := 0; row < MaxSGFSize; row++ {
+				s, err := Format(row, MaxSGFSize-1-row, MaxSGFSize, SGF)
+				Expect(err).ToNot(HaveOccurred())
+				r, c, err := Parse(s, MaxSGFSize, SGF)
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]int{r, c}).To(Equal([]int{row, MaxSGFSize - 1 - row}))
+			}
+		})
+	})
+})
@@ -0,0 +1,141 @@
+package ggo
+
+// Event is something happened in the game. Observers get one of the
+// event types below.
+type Event interface {
+	event()
+}
+
+// StonePlaced is a move or a setup stone put on the board.
+type StonePlaced struct {
+	Move  Move
+	Setup bool
+}
+
+// StonesCaptured is stones removed from the board by the move. Colors
+// of the stones are colors they had before capture.
+type StonesCaptured struct {
+	Stones []Move
+}
+
+type Passed struct {
+	Color Color
+}
+
+// KoCreated is place where the next move is disallowed by ko.
+type KoCreated struct {
+	Row    int
+	Column int
+}
+
+type PhaseChanged struct {
+	Phase Phase
+}
+
+// BaseTimeOver is main time of color run out, clock is in byo-yomi.
+type BaseTimeOver struct {
+	Color Color
+}
+
+// PeriodOver is byo-yomi period of color run out, Periods are left.
+type PeriodOver struct {
+	Color   Color
+	Periods int
+}
+
+// TimeOver is all time of color run out.
+type TimeOver struct {
+	Color Color
+}
+
+// Adjourned is the game stopped with clocks frozen, Resumed is its
+// continuation.
+type Adjourned struct{}
+
+type Resumed struct{}
+
+// GameOver is the end of the game with the result in SGF form, which is
+// empty when the result is not known yet, e.g. after two passes.
+type GameOver struct {
+	Result string
+}
+
+func (StonePlaced) event()    {}
+func (StonesCaptured) event() {}
+func (Passed) event()         {}
+func (KoCreated) event()      {}
+func (PhaseChanged) event()   {}
+func (BaseTimeOver) event()   {}
+func (PeriodOver) event()     {}
+func (TimeOver) event()       {}
+func (Adjourned) event()      {}
+func (Resumed) event()        {}
+func (GameOver) event()       {}
+
+// Observer is notified about events of the game. Observers are called
+// in the order of subscription, after the game state is updated and
+// without game lock held, so they may call the game. Events are
+// delivered in the order they happened by one goroutine at a time, the
+// caller of game method or a clock goroutine. When other delivery is in
+// progress, including the call from observer, the method returns before
+// its events are delivered, they follow the events delivered now.
+type Observer interface {
+	OnEvent(e Event)
+}
+
+// ObserverFunc is function used as Observer.
+type ObserverFunc func(e Event)
+
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
+
+// Subscribe adds observer of the game events and returns function
+// removing it.
+func (g *Game) Subscribe(o Observer) func() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.observerID++
+	id := g.observerID
+	g.observers = append(g.observers, subscription{id: id, observer: o})
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for i, s := range g.observers {
+			if s.id == id {
+				g.observers = append(g.observers[:i:i], g.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+type subscription struct {
+	id       int
+	observer Observer
+}
+
+// flush sends pending events to observers. Only one goroutine delivers
+// at a time, events pending meanwhile are delivered by it, so batches of
+// moves and clocks never interleave.
+func (g *Game) flush() {
+	g.mu.Lock()
+	if g.delivering {
+		g.mu.Unlock()
+		return
+	}
+	g.delivering = true
+	for len(g.pending) > 0 {
+		events, observers := g.pending, g.observers
+		g.pending = nil
+		g.mu.Unlock()
+		for _, e := range events {
+			for _, s := range observers {
+				s.observer.OnEvent(e)
+			}
+		}
+		g.mu.Lock()
+	}
+	g.delivering = false
+	g.mu.Unlock()
+}
@@ -2,61 +2,438 @@ package ggo
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/someanon/ggo/coord"
 	"github.com/someanon/ggo/timer"
 )
 
 type Parameters struct {
 	BoardSize  int               `json:"boardSize"`
+	Komi       float64           `json:"komi,omitempty"`
 	TimeSystem *timer.Parameters `json:"timeSystem"`
+	// WhiteTimeSystem is time system of white when it differs from time
+	// system of black, e.g. time odds in teaching games.
+	WhiteTimeSystem *timer.Parameters `json:"whiteTimeSystem,omitempty"`
 }
 
+// TimeSystemOf returns time system of color, nil is no time limit.
+func (p Parameters) TimeSystemOf(color Color) *timer.Parameters {
+	if color == White && p.WhiteTimeSystem != nil {
+		return p.WhiteTimeSystem
+	}
+	return p.TimeSystem
+}
+
+func (p Parameters) Validate() error {
+	if p.BoardSize < 1 {
+		return errors.New("board size should be greater than zero")
+	}
+	if p.TimeSystem == nil {
+		if p.WhiteTimeSystem != nil {
+			return errors.New("white time system requires time system of black")
+		}
+		return nil
+	}
+	if err := p.TimeSystem.Validate(); err != nil {
+		return err
+	}
+	if p.WhiteTimeSystem == nil {
+		return nil
+	}
+	if err := p.WhiteTimeSystem.Validate(); err != nil {
+		return fmt.Errorf("white time system: %v", err)
+	}
+	if (p.TimeSystem.System == timer.Hourglass) != (p.WhiteTimeSystem.System == timer.Hourglass) {
+		return errors.New("hourglass system should be used by both colors")
+	}
+	return nil
+}
+
+// Info is game metadata, which doesn't affect play.
+type Info struct {
+	BlackName string `json:"blackName,omitempty"`
+	BlackRank string `json:"blackRank,omitempty"`
+	WhiteName string `json:"whiteName,omitempty"`
+	WhiteRank string `json:"whiteRank,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Event     string `json:"event,omitempty"`
+	Place     string `json:"place,omitempty"`
+	// Result is recorded result in SGF form, e.g. "B+3.5" or "W+R".
+	Result string `json:"result,omitempty"`
+}
+
+type Move struct {
+	Color  Color `json:"color"`
+	Row    int   `json:"row"`
+	Column int   `json:"column"`
+	Pass   bool  `json:"pass,omitempty"`
+	// Time is clock record of the move in history, nil when clocks
+	// don't run.
+	Time *MoveTime `json:"time,omitempty"`
+}
+
+// MoveTime is time taken by the move and clock of the player after it.
+type MoveTime struct {
+	// Elapsed is zero when unknown, e.g. in imported records.
+	Elapsed time.Duration
+	// Left is main time left, or time of byo-yomi period when main time
+	// is over.
+	Left time.Duration
+	// Overtime is zero in main time, otherwise byo-yomi periods left, or
+	// stones left to play in Canadian period, as OB and OW of SGF.
+	Overtime int
+}
+
+// Game is safe for concurrent use, since clocks end the game from their
+// own goroutines.
 type Game struct {
+	mu               sync.Mutex
 	parameters       Parameters
+	info             Info
 	board            *board
-	timer            *timer.Timer
+	clock            timer.Clock
+	clocks           map[Color]*timer.Timer
+	savedClocks      map[Color]timer.State
+	running          Color
+	adjourned        bool
+	think            *time.Duration
 	moveColor        Color
 	moveID           int
 	disallowedPlaces map[[2]int]nothing
+	setup            []Move
+	history          []Move
+	prisoners        map[Color]int
+	phase            Phase
+	result           *Result
+	pending          []Event
+	delivering       bool
+	observers        []subscription
+	observerID       int
 }
 
 func NewGame(parameters Parameters) *Game {
-	g := &Game{
-		parameters:       parameters,
-		board:            newBoard(parameters.BoardSize),
-		timer:            nil,
-		moveColor:        Black,
-		moveID:           1,
-		disallowedPlaces: nil,
-	}
-	g.computeDisallowedMoves()
+	g := &Game{}
+	g.init(parameters)
 	return g
 }
 
+func (g *Game) init(parameters Parameters) {
+	g.parameters = parameters
+	g.info = Info{}
+	g.board = newBoard(parameters.BoardSize)
+	g.clocks = nil
+	g.savedClocks = nil
+	g.running = Empty
+	g.adjourned = false
+	g.think = nil
+	g.moveColor = Black
+	g.moveID = 1
+	g.disallowedPlaces = nil
+	g.setup = make([]Move, 0)
+	g.history = make([]Move, 0)
+	g.prisoners = map[Color]int{Black: 0, White: 0}
+	g.phase = Playing
+	g.result = nil
+	g.pending = nil
+	g.computeDisallowedMoves()
+}
+
 func (g *Game) Move(row int, column int, color Color) error {
+	g.mu.Lock()
+	err := g.move(row, column, color)
+	g.mu.Unlock()
+	g.flush()
+	return err
+}
+
+func (g *Game) move(row int, column int, color Color) error {
+	if g.phase == Over {
+		return errors.New("game is over")
+	}
+	if g.adjourned {
+		return errors.New("game is adjourned")
+	}
 	if g.moveColor != color {
 		return errors.New("turn of another color")
 	}
 	if _, exists := g.disallowedPlaces[[2]int{row, column}]; exists {
 		return errors.New("move is disallowed")
 	}
-	err := g.board.put(row, column, color)
+	captured, err := g.board.put(row, column, color)
 	if err != nil {
 		return err
 	}
+	g.prisoners[color] += len(captured)
+	move := Move{Color: color, Row: row, Column: column}
+	g.history = append(g.history, move)
 	g.nextMove()
 	g.computeDisallowedMoves()
+	g.switchClocks(g.moveColor)
+
+	g.pending = append(g.pending, StonePlaced{Move: g.history[len(g.history)-1]})
+	if len(captured) > 0 {
+		stones := make([]Move, len(captured))
+		for i, p := range captured {
+			stones[i] = Move{Color: g.nextColor(color), Row: p.row, Column: p.column}
+		}
+		g.pending = append(g.pending, StonesCaptured{Stones: stones})
+	}
+	if ko := g.board.koPlace; ko != nil {
+		g.pending = append(g.pending, KoCreated{Row: ko.row, Column: ko.column})
+	}
+	return nil
+}
+
+// Play makes move or pass written in any notation of coord package, e.g.
+// "Q16", "pd", "3,15", "4-4" or "pass".
+func (g *Game) Play(place string, color Color) error {
+	n, err := coord.Detect(place)
+	if err != nil {
+		return err
+	}
+	size := g.Size()
+	if coord.IsPass(place, size, n) {
+		return g.Pass(color)
+	}
+	row, column, err := coord.Parse(place, size, n)
+	if err != nil {
+		return err
+	}
+	return g.Move(row, column, color)
+}
+
+// Setup puts stone of color without taking turn, e.g. handicap stone.
+// Setup is allowed only before the first move.
+func (g *Game) Setup(row int, column int, color Color) error {
+	g.mu.Lock()
+	err := g.setupStone(row, column, color)
+	g.mu.Unlock()
+	g.flush()
+	return err
+}
+
+func (g *Game) setupStone(row int, column int, color Color) error {
+	if len(g.history) > 0 {
+		return errors.New("setup is allowed only before the first move")
+	}
+	p, err := g.board.place(row, column)
+	if err != nil {
+		return err
+	}
+	if _, _, dyingEnemyGroups := p.analyzeNeighbors(color); len(dyingEnemyGroups) > 0 {
+		return errors.New("setup stone shouldn't capture")
+	}
+	if _, err := p.put(color); err != nil {
+		return err
+	}
+	g.board.koPlace = nil
+	stone := Move{Color: color, Row: row, Column: column}
+	g.setup = append(g.setup, stone)
+	g.computeDisallowedMoves()
+	g.pending = append(g.pending, StonePlaced{Move: stone, Setup: true})
+	return nil
+}
+
+// SetMoveColor sets color of the first move, e.g. white after handicap.
+func (g *Game) SetMoveColor(color Color) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.setMoveColor(color)
+}
+
+func (g *Game) setMoveColor(color Color) error {
+	if len(g.history) > 0 {
+		return errors.New("move color can be set only before the first move")
+	}
+	if color != Black && color != White {
+		return errors.New("move color should be black or white")
+	}
+	if g.adjourned {
+		return errors.New("game is adjourned")
+	}
+	g.moveColor = color
+	g.computeDisallowedMoves()
+	if g.running != Empty {
+		g.switchClocks(color)
+	}
 	return nil
 }
 
 func (g *Game) Pass(color Color) error {
+	g.mu.Lock()
+	err := g.pass(color)
+	g.mu.Unlock()
+	g.flush()
+	return err
+}
+
+func (g *Game) pass(color Color) error {
+	if g.phase == Over {
+		return errors.New("game is over")
+	}
+	if g.adjourned {
+		return errors.New("game is adjourned")
+	}
 	if g.moveColor != color {
 		return errors.New("turn of another color")
 	}
+	g.history = append(g.history, Move{Color: color, Pass: true})
+	g.pending = append(g.pending, Passed{Color: color})
+	g.board.koPlace = nil
 	g.nextMove()
+	g.computeDisallowedMoves()
+	if n := len(g.history); n >= 2 && g.history[n-2].Pass {
+		g.finish(nil)
+	} else {
+		g.switchClocks(g.moveColor)
+	}
 	return nil
 }
 
+func (g *Game) Parameters() Parameters {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.parameters
+}
+
+func (g *Game) Info() Info {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.info
+}
+
+func (g *Game) SetInfo(info Info) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.info = info
+}
+
+func (g *Game) Size() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.board.size
+}
+
+// Position returns colors of board places indexed by row and column.
+func (g *Game) Position() [][]Color {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	position := make([][]Color, g.board.size)
+	for r := 0; r < g.board.size; r++ {
+		position[r] = make([]Color, g.board.size)
+		for c := 0; c < g.board.size; c++ {
+			position[r][c] = g.board.places[r][c].color
+		}
+	}
+	return position
+}
+
+func (g *Game) Phase() Phase {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.phase
+}
+
+func (g *Game) MoveColor() Color {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.moveColor
+}
+
+// Prisoners returns count of stones captured by color.
+func (g *Game) Prisoners(color Color) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.prisoners[color]
+}
+
+// SetupStones returns copy of the setup stones.
+func (g *Game) SetupStones() []Move {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	stones := make([]Move, len(g.setup))
+	copy(stones, g.setup)
+	return stones
+}
+
+// SetMoveTime sets clock record of move number n starting with 1, e.g.
+// read from imported record.
+func (g *Game) SetMoveTime(n int, t *MoveTime) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n < 1 || n > len(g.history) {
+		return fmt.Errorf("move number should be between 1 and %d", len(g.history))
+	}
+	g.history[n-1].Time = t
+	return nil
+}
+
+// Moves returns copy of the game history.
+func (g *Game) Moves() []Move {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	moves := make([]Move, len(g.history))
+	copy(moves, g.history)
+	return moves
+}
+
+// AfterMoves returns new game replayed from the setup through the first
+// n moves of this game.
+func (g *Game) AfterMoves(n int) (*Game, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n < 0 || n > len(g.history) {
+		return nil, fmt.Errorf("moves count should be between 0 and %d", len(g.history))
+	}
+	r := &Game{}
+	if err := r.replay(g.parameters, g.setup, g.firstMoveColor(), g.history[:n]); err != nil {
+		return nil, err
+	}
+	r.info = g.info
+	return r, nil
+}
+
+// replay resets game to parameters, puts setup stones and plays moves
+// starting with moveColor. Events of replay are dropped.
+func (g *Game) replay(parameters Parameters, setup []Move, moveColor Color, moves []Move) error {
+	g.init(parameters)
+	for _, s := range setup {
+		if err := g.setupStone(s.Row, s.Column, s.Color); err != nil {
+			return fmt.Errorf("failed to setup stone: %v", err)
+		}
+	}
+	if moveColor != Empty {
+		g.moveColor = moveColor
+		g.computeDisallowedMoves()
+	}
+	for i, m := range moves {
+		var err error
+		if m.Pass {
+			err = g.pass(m.Color)
+		} else {
+			err = g.move(m.Row, m.Column, m.Color)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to replay move %d: %v", i+1, err)
+		}
+		g.history[i].Time = m.Time
+	}
+	g.pending = nil
+	return nil
+}
+
+// firstMoveColor returns color of the first move, which is not black
+// when game is set up with white to play.
+func (g *Game) firstMoveColor() Color {
+	if len(g.history) > 0 {
+		return g.history[0].Color
+	}
+	return g.moveColor
+}
+
 func (g *Game) nextMove() {
 	g.moveColor = g.nextColor(g.moveColor)
 	g.moveID++
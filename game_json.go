@@ -0,0 +1,130 @@
+package ggo
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/someanon/ggo/timer"
+)
+
+type gameJSON struct {
+	Parameters Parameters            `json:"parameters"`
+	Info       Info                  `json:"info"`
+	Setup      []Move                `json:"setup,omitempty"`
+	MoveColor  Color                 `json:"moveColor"`
+	Moves      []Move                `json:"moves"`
+	Position   []string              `json:"position"`
+	Prisoners  map[Color]int         `json:"prisoners"`
+	Phase      Phase                 `json:"phase"`
+	Result     *Result               `json:"result,omitempty"`
+	Clocks     map[Color]timer.State `json:"clocks,omitempty"`
+	Adjourned  bool                  `json:"adjourned,omitempty"`
+}
+
+func (g *Game) MarshalJSON() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return json.Marshal(gameJSON{
+		Parameters: g.parameters,
+		Info:       g.info,
+		Setup:      g.setup,
+		MoveColor:  g.firstMoveColor(),
+		Moves:      g.history,
+		Position:   g.board.rows(),
+		Prisoners:  g.prisoners,
+		Phase:      g.phase,
+		Result:     g.result,
+		Clocks:     g.clockStates(),
+		Adjourned:  g.adjourned,
+	})
+}
+
+// UnmarshalJSON restores game by replaying saved moves and checks that
+// the result matches saved position, prisoners and phase. Game over by
+// time or resignation is restored from its result. Clocks are not
+// started, StartClocks continues them from saved state. Game with
+// started clocks can't be restored into.
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var gj gameJSON
+	if err := json.Unmarshal(data, &gj); err != nil {
+		return err
+	}
+	if err := gj.Parameters.Validate(); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.clocks != nil {
+		return errors.New("clocks are already started")
+	}
+	// moveColor is color of the first move since setup stones, moves
+	// replayed after it keep the turn order.
+	if len(gj.Moves) > 0 && gj.MoveColor != Empty && gj.Moves[0].Color != gj.MoveColor {
+		return errors.New("move color doesn't match moves")
+	}
+	if err := g.replay(gj.Parameters, gj.Setup, gj.MoveColor, gj.Moves); err != nil {
+		return err
+	}
+	g.info = gj.Info
+	g.savedClocks = gj.Clocks
+	if gj.Result != nil {
+		if g.phase == Playing {
+			g.finish(gj.Result)
+			g.pending = nil
+		} else {
+			g.result = gj.Result
+		}
+	}
+	g.adjourned = gj.Adjourned && g.phase == Playing
+
+	if gj.Position != nil {
+		rows := g.board.rows()
+		if len(rows) != len(gj.Position) {
+			return errors.New("position doesn't match moves")
+		}
+		for r := range rows {
+			if rows[r] != gj.Position[r] {
+				return errors.New("position doesn't match moves")
+			}
+		}
+	}
+	for color, count := range gj.Prisoners {
+		if g.prisoners[color] != count {
+			return errors.New("prisoners don't match moves")
+		}
+	}
+	if g.phase != gj.Phase {
+		return errors.New("phase doesn't match moves")
+	}
+	return nil
+}
+
+// moveTimeJSON has durations in seconds as time system parameters.
+type moveTimeJSON struct {
+	Elapsed  timer.Duration `json:"elapsed,omitempty"`
+	Left     timer.Duration `json:"left"`
+	Overtime int            `json:"overtime,omitempty"`
+}
+
+func (t MoveTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moveTimeJSON{
+		Elapsed:  timer.Duration(t.Elapsed),
+		Left:     timer.Duration(t.Left),
+		Overtime: t.Overtime,
+	})
+}
+
+func (t *MoveTime) UnmarshalJSON(data []byte) error {
+	var tj moveTimeJSON
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return err
+	}
+	*t = MoveTime{
+		Elapsed:  time.Duration(tj.Elapsed),
+		Left:     time.Duration(tj.Left),
+		Overtime: tj.Overtime,
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package ggo
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Game", func() {
+	var g *Game
+	BeforeEach(func() {
+		g = NewGame(Parameters{BoardSize: 5})
+	})
+	Describe("playing", func() {
+		It("counts prisoners", func() {
+			Expect(g.Move(0, 1, Black)).To(Succeed())
+			Expect(g.Move(0, 0, White)).To(Succeed())
+			Expect(g.Move(1, 0, Black)).To(Succeed())
+			Expect(g.Prisoners(Black)).To(Equal(1))
+			Expect(g.Prisoners(White)).To(Equal(0))
+			Expect(g.board.places[0][0].color).To(Equal(Empty))
+		})
+		It("accepts moves in coord notations", func() {
+			Expect(g.Play("B5", Black)).To(Succeed())
+			Expect(g.Play("aa", White)).To(Succeed())
+			Expect(g.Play("1,0", Black)).To(Succeed())
+			Expect(g.Play("1-5", White)).To(Succeed())
+			Expect(g.Play("pass", Black)).To(Succeed())
+			Expect(g.Play("Z9", White)).ToNot(Succeed())
+			Expect(g.Prisoners(Black)).To(Equal(1))
+			Expect(g.Position()[4][4]).To(Equal(White))
+			Expect(g.Moves()[4].Pass).To(BeTrue())
+		})
+		It("is over after two passes in a row", func() {
+			Expect(g.Pass(Black)).To(Succeed())
+			Expect(g.Move(2, 2, White)).To(Succeed())
+			Expect(g.Pass(Black)).To(Succeed())
+			Expect(g.Phase()).To(Equal(Playing))
+			Expect(g.Pass(White)).To(Succeed())
+			Expect(g.Phase()).To(Equal(Over))
+			Expect(g.Move(1, 1, Black)).ToNot(Succeed())
+		})
+	})
+	Describe("events", func() {
+		var events []Event
+		BeforeEach(func() {
+			events = nil
+			g.Subscribe(ObserverFunc(func(e Event) {
+				events = append(events, e)
+			}))
+		})
+		It("tells about placed stones", func() {
+			Expect(g.Setup(0, 1, White)).To(Succeed())
+			Expect(g.Move(2, 2, Black)).To(Succeed())
+			Expect(events).To(Equal([]Event{
+				StonePlaced{Move: Move{Color: White, Row: 0, Column: 1}, Setup: true},
+				StonePlaced{Move: Move{Color: Black, Row: 2, Column: 2}},
+			}))
+		})
+		It("tells about captures and ko", func() {
+			for _, m := range []Move{
+				{Color: Black, Row: 0, Column: 1}, {Color: White, Row: 0, Column: 2},
+				{Color: Black, Row: 1, Column: 0}, {Color: White, Row: 1, Column: 1},
+				{Color: Black, Row: 4, Column: 4}, {Color: White, Row: 0, Column: 0},
+			} {
+				Expect(g.Move(m.Row, m.Column, m.Color)).To(Succeed())
+			}
+			Expect(events[len(events)-3:]).To(Equal([]Event{
+				StonePlaced{Move: Move{Color: White, Row: 0, Column: 0}},
+				StonesCaptured{Stones: []Move{{Color: Black, Row: 0, Column: 1}}},
+				KoCreated{Row: 0, Column: 1},
+			}))
+		})
+		It("tells about passes and the end of the game", func() {
+			Expect(g.Pass(Black)).To(Succeed())
+			Expect(g.Pass(White)).To(Succeed())
+			Expect(events).To(Equal([]Event{
+				Passed{Color: Black},
+				Passed{Color: White},
+				PhaseChanged{Phase: Over},
+				GameOver{},
+			}))
+		})
+		It("tells about events of observer calls after the current event", func() {
+			g = NewGame(Parameters{BoardSize: 5})
+			g.Subscribe(ObserverFunc(func(e Event) {
+				if _, ok := e.(StonePlaced); ok {
+					g.Pass(White)
+				}
+			}))
+			g.Subscribe(ObserverFunc(func(e Event) {
+				events = append(events, e)
+			}))
+			Expect(g.Move(2, 2, Black)).To(Succeed())
+			Expect(events).To(Equal([]Event{
+				StonePlaced{Move: Move{Color: Black, Row: 2, Column: 2}},
+				Passed{Color: White},
+			}))
+		})
+		It("stops telling after unsubscribe", func() {
+			unsubscribe := g.Subscribe(ObserverFunc(func(e Event) {}))
+			unsubscribe()
+			var other []Event
+			g.Subscribe(ObserverFunc(func(e Event) {
+				other = append(other, e)
+			}))()
+			Expect(g.Move(2, 2, Black)).To(Succeed())
+			Expect(events).To(HaveLen(1))
+			Expect(other).To(BeEmpty())
+		})
+	})
+	Describe("handicap", func() {
+		It("puts stones on star points", func() {
+			g = NewGame(Parameters{BoardSize: 19})
+			Expect(g.SetupHandicap(9)).To(Succeed())
+			Expect(g.SetupStones()).To(HaveLen(9))
+			Expect(g.MoveColor()).To(Equal(White))
+			for _, s := range g.SetupStones() {
+				Expect(s.Row % 6).To(Equal(3))
+				Expect(s.Column % 6).To(Equal(3))
+			}
+		})
+		It("rejects too many stones", func() {
+			Expect(g.SetupHandicap(2)).ToNot(Succeed())
+			g = NewGame(Parameters{BoardSize: 9})
+			Expect(g.SetupHandicap(10)).ToNot(Succeed())
+			g = NewGame(Parameters{BoardSize: 10})
+			Expect(g.SetupHandicap(5)).ToNot(Succeed())
+		})
+	})
+	Describe("replaying", func() {
+		It("returns game after first moves", func() {
+			Expect(g.Move(0, 1, Black)).To(Succeed())
+			Expect(g.Move(0, 0, White)).To(Succeed())
+			Expect(g.Move(1, 0, Black)).To(Succeed())
+			r, err := g.AfterMoves(2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Moves()).To(Equal(g.Moves()[:2]))
+			Expect(r.Position()[0][0]).To(Equal(White))
+			Expect(r.MoveColor()).To(Equal(Black))
+			_, err = g.AfterMoves(4)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("JSON", func() {
+		BeforeEach(func() {
+			Expect(g.Move(0, 1, Black)).To(Succeed())
+			Expect(g.Move(0, 0, White)).To(Succeed())
+			Expect(g.Move(1, 0, Black)).To(Succeed())
+			Expect(g.Pass(White)).To(Succeed())
+			Expect(g.Move(3, 3, Black)).To(Succeed())
+		})
+		It("restores game exactly", func() {
+			data, err := json.Marshal(g)
+			Expect(err).ToNot(HaveOccurred())
+			restored := &Game{}
+			Expect(json.Unmarshal(data, restored)).To(Succeed())
+			Expect(restored.Parameters()).To(Equal(g.Parameters()))
+			Expect(restored.Moves()).To(Equal(g.Moves()))
+			Expect(restored.board.rows()).To(Equal(g.board.rows()))
+			Expect(restored.Prisoners(Black)).To(Equal(1))
+			Expect(restored.Phase()).To(Equal(Playing))
+			Expect(restored.MoveColor()).To(Equal(White))
+			Expect(restored.Move(0, 0, White)).ToNot(Succeed())
+			Expect(restored.Move(4, 4, White)).To(Succeed())
+		})
+		It("restores setup stones", func() {
+			sg, err := ParseGame(`
+				. X . . .
+				. . . . .
+				. . O . .
+				. . . . .
+				. . . . .
+			`, White)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sg.Move(0, 0, White)).To(Succeed())
+			data, err := json.Marshal(sg)
+			Expect(err).ToNot(HaveOccurred())
+			restored := &Game{}
+			Expect(json.Unmarshal(data, restored)).To(Succeed())
+			Expect(restored.SetupStones()).To(Equal(sg.SetupStones()))
+			Expect(restored.String()).To(Equal(sg.String()))
+		})
+		It("rejects inconsistent position", func() {
+			data, err := json.Marshal(g)
+			Expect(err).ToNot(HaveOccurred())
+			var raw map[string]interface{}
+			Expect(json.Unmarshal(data, &raw)).To(Succeed())
+			raw["position"] = []string{".....", ".....", ".....", ".....", "....."}
+			data, err = json.Marshal(raw)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(json.Unmarshal(data, &Game{})).ToNot(Succeed())
+		})
+		It("rejects move color that doesn't match moves", func() {
+			data, err := json.Marshal(g)
+			Expect(err).ToNot(HaveOccurred())
+			var raw map[string]interface{}
+			Expect(json.Unmarshal(data, &raw)).To(Succeed())
+			Expect(raw["moveColor"]).To(Equal("black"))
+			raw["moveColor"] = "white"
+			data, err = json.Marshal(raw)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(json.Unmarshal(data, &Game{})).To(MatchError("move color doesn't match moves"))
+		})
+	})
+})
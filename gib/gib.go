@@ -0,0 +1,232 @@
+// Package gib reads GIB game records of Tygem.
+//
+// Header lines are "\[KEY=VALUE\]" between "\HS" and "\HE", komi and
+// result are taken from GAMEINFOMAIN fields GONGJE, GRLT and ZIPSU. Game
+// lines are "INI 0 1 <handicap>", "STO 0 <number> <color> <x> <y>" with
+// color 1 for black and 2 for white, and "SKI 0 <number>" for pass.
+// GTIME field is time system "<base>-<byo-yomi>-<periods>" in seconds.
+package gib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/sgf"
+	"github.com/someanon/ggo/timer"
+)
+
+const boardSize = 19
+
+// Read reads game record and replays it.
+func Read(r io.Reader) (*ggo.Game, error) {
+	header := make(map[string]string)
+	var lines []string
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, `\[`) && strings.HasSuffix(line, `\]`) {
+			kv := line[2 : len(line)-2]
+			if i := strings.IndexByte(kv, '='); i > 0 {
+				header[kv[:i]] = strings.TrimSpace(kv[i+1:])
+			}
+			continue
+		}
+		if line != "" && !strings.HasPrefix(line, `\`) {
+			lines = append(lines, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	info := infoMain(header["GAMEINFOMAIN"])
+
+	parameters := ggo.Parameters{BoardSize: boardSize}
+	if v, ok := info["GONGJE"]; ok {
+		komi, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("gib: invalid komi %q", v)
+		}
+		parameters.Komi = float64(komi) / 10
+	}
+	if v, ok := info["GTIME"]; ok {
+		ts, err := timeSystem(v)
+		if err != nil {
+			return nil, err
+		}
+		parameters.TimeSystem = ts
+	}
+
+	g := ggo.NewGame(parameters)
+	blackName, blackRank := player(header["GAMEBLACKNAME"])
+	whiteName, whiteRank := player(header["GAMEWHITENAME"])
+	if v := header["GAMEBLACKLEVEL"]; v != "" && blackRank == "" {
+		blackRank = v
+	}
+	if v := header["GAMEWHITELEVEL"]; v != "" && whiteRank == "" {
+		whiteRank = v
+	}
+	g.SetInfo(ggo.Info{
+		BlackName: blackName,
+		BlackRank: blackRank,
+		WhiteName: whiteName,
+		WhiteRank: whiteRank,
+		Date:      date(header["GAMEDATE"]),
+		Event:     header["GAMENAME"],
+		Place:     header["GAMEPLACE"],
+		Result:    result(info["GRLT"], info["ZIPSU"]),
+	})
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		numbers := make([]int, 0, len(fields)-1)
+		for _, f := range fields[1:] {
+			n, err := strconv.Atoi(strings.TrimPrefix(f, "&"))
+			if err != nil {
+				break
+			}
+			numbers = append(numbers, n)
+		}
+		switch fields[0] {
+		case "INI":
+			if len(numbers) < 3 {
+				return nil, fmt.Errorf("gib: invalid line %q", line)
+			}
+			if handicap := numbers[2]; handicap > 1 {
+				if err := g.SetupHandicap(handicap); err != nil {
+					return nil, fmt.Errorf("gib: %v", err)
+				}
+			}
+		case "STO":
+			if len(numbers) < 5 {
+				return nil, fmt.Errorf("gib: invalid line %q", line)
+			}
+			color := ggo.Black
+			if numbers[2] == 2 {
+				color = ggo.White
+			}
+			if len(g.Moves()) == 0 && g.MoveColor() != color {
+				if err := g.SetMoveColor(color); err != nil {
+					return nil, fmt.Errorf("gib: %v", err)
+				}
+			}
+			if err := g.Move(numbers[4], numbers[3], color); err != nil {
+				return nil, fmt.Errorf("gib: move %d: %v", numbers[1], err)
+			}
+		case "SKI":
+			if err := g.Pass(g.MoveColor()); err != nil {
+				return nil, fmt.Errorf("gib: pass: %v", err)
+			}
+		}
+	}
+	return g, nil
+}
+
+// ToSGF converts game record to SGF.
+func ToSGF(r io.Reader, w io.Writer) error {
+	return sgf.Convert(Read, r, w)
+}
+
+// timeSystem converts "1200-30-3" value, zero base and byo-yomi is no
+// time limit.
+func timeSystem(v string) (*timer.Parameters, error) {
+	fields := strings.Split(v, "-")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("gib: invalid time %q", v)
+	}
+	values := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("gib: invalid time %q", v)
+		}
+		values[i] = n
+	}
+	base, byoYomi, periods := values[0], values[1], values[2]
+	if base == 0 && byoYomi == 0 {
+		return nil, nil
+	}
+	p := &timer.Parameters{System: timer.Absolute, Base: time.Duration(base) * time.Second}
+	if byoYomi > 0 {
+		p = &timer.Parameters{
+			Base:    time.Duration(base) * time.Second,
+			ByoYomi: time.Duration(byoYomi) * time.Second,
+			Periods: periods,
+			Moves:   1,
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("gib: invalid time %q: %v", v, err)
+	}
+	return p, nil
+}
+
+// infoMain splits "GBLACK:a,GWHITE:b,GRLT:0,..." value.
+func infoMain(v string) map[string]string {
+	info := make(map[string]string)
+	for _, f := range strings.Split(v, ",") {
+		if i := strings.IndexByte(f, ':'); i > 0 {
+			info[strings.TrimSpace(f[:i])] = strings.TrimSpace(f[i+1:])
+		}
+	}
+	return info
+}
+
+// player splits "name (rank)" value.
+func player(v string) (string, string) {
+	if i := strings.LastIndexByte(v, '('); i >= 0 && strings.HasSuffix(v, ")") {
+		return strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1 : len(v)-1])
+	}
+	return strings.TrimSpace(v), ""
+}
+
+// date converts "2010- 3-31-20-15-33" to "2010-03-31".
+func date(v string) string {
+	fields := strings.FieldsFunc(v, func(r rune) bool {
+		return r == '-' || r == ' ' || r == '/' || r == '.'
+	})
+	if len(fields) < 3 {
+		return strings.TrimSpace(v)
+	}
+	parts := make([]int, 3)
+	for i := range parts {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return strings.TrimSpace(v)
+		}
+		parts[i] = n
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", parts[0], parts[1], parts[2])
+}
+
+// result converts GRLT code and ZIPSU score multiplied by ten to SGF
+// form.
+func result(code string, score string) string {
+	switch code {
+	case "0", "1":
+		winner := "B"
+		if code == "1" {
+			winner = "W"
+		}
+		n, err := strconv.Atoi(score)
+		if err != nil {
+			return winner + "+"
+		}
+		return winner + "+" + strconv.FormatFloat(float64(n)/10, 'f', -1, 64)
+	case "3":
+		return "B+R"
+	case "4":
+		return "W+R"
+	case "7":
+		return "B+T"
+	case "8":
+		return "W+T"
+	}
+	return ""
+}
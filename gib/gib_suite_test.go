@@ -0,0 +1,13 @@
+package gib_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGib(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gib Suite")
+}
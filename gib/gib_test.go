@@ -0,0 +1,90 @@
+package gib_test
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/gib"
+	"github.com/someanon/ggo/sgf"
+	"github.com/someanon/ggo/timer"
+)
+
+const record = `\HS
+\[GAMENAME=Ranked\]
+\[GAMEDATE=2010- 3-31-20-15-33\]
+\[GAMEBLACKNAME=black (5D)\]
+\[GAMEWHITENAME=white (6D)\]
+\[GAMEINFOMAIN=GBKIND:3,GTIME:1200-30-3,GRLT:1,ZIPSU:35,GONGJE:65\]
+\HE
+\GS
+2 1 0
+119 0 &4
+INI 0 1 0 &4
+STO 0 2 1 15 3
+STO 0 3 2 3 15
+SKI 0 4
+STO 0 5 2 15 15
+\GE
+`
+
+var _ = Describe("GIB", func() {
+	It("reads game", func() {
+		g, err := Read(strings.NewReader(record))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.Size()).To(Equal(19))
+		Expect(g.Parameters().Komi).To(Equal(6.5))
+		Expect(g.Info()).To(Equal(ggo.Info{
+			BlackName: "black",
+			BlackRank: "5D",
+			WhiteName: "white",
+			WhiteRank: "6D",
+			Date:      "2010-03-31",
+			Event:     "Ranked",
+			Result:    "W+3.5",
+		}))
+		Expect(g.Moves()).To(Equal([]ggo.Move{
+			{Color: ggo.Black, Row: 3, Column: 15},
+			{Color: ggo.White, Row: 15, Column: 3},
+			{Color: ggo.Black, Pass: true},
+			{Color: ggo.White, Row: 15, Column: 15},
+		}))
+	})
+	It("reads time system", func() {
+		g, err := Read(strings.NewReader(record))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.Parameters().TimeSystem).To(Equal(&timer.Parameters{
+			Base: 20 * time.Minute, ByoYomi: 30 * time.Second, Periods: 3, Moves: 1,
+		}))
+		for v, ts := range map[string]*timer.Parameters{
+			"600-0-0": {System: timer.Absolute, Base: 10 * time.Minute},
+			"0-0-0":   nil,
+		} {
+			g, err := Read(strings.NewReader(`\[GAMEINFOMAIN=GTIME:` + v + `\]`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Parameters().TimeSystem).To(Equal(ts), v)
+		}
+		for _, v := range []string{"600-30", "600-30-0", "ten-30-3"} {
+			_, err := Read(strings.NewReader(`\[GAMEINFOMAIN=GTIME:` + v + `\]`))
+			Expect(err).To(HaveOccurred(), v)
+		}
+	})
+	It("puts handicap stones", func() {
+		g, err := Read(strings.NewReader("\\GS\nINI 0 1 4 &4\nSTO 0 2 2 2 2\n\\GE\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.SetupStones()).To(HaveLen(4))
+		Expect(g.Moves()[0].Color).To(Equal(ggo.White))
+	})
+	It("converts game to SGF", func() {
+		var buf bytes.Buffer
+		Expect(ToSGF(strings.NewReader(record), &buf)).To(Succeed())
+		trees, err := sgf.Parse(&buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(trees[0].Values("KM")).To(Equal([]string{"6.5"}))
+		Expect(trees[0].Values("OT")).To(Equal([]string{"3x30 byo-yomi"}))
+		Expect(trees[0].Children[0].Values("B")).To(Equal([]string{"pd"}))
+	})
+})
@@ -1,8 +1,10 @@
 package ggo
 
 type group struct {
-	places    []*place
-	liberties int
+	places []*place
+	// count is liberties counted when board version was countedAt-1.
+	count     int
+	countedAt int
 }
 
 func (g *group) join(joinGroup *group) {
@@ -17,3 +19,23 @@ func (g *group) die() {
 		p.die()
 	}
 }
+
+// liberties counts empty places next to the group. The count is kept
+// until any stone is put or removed, so disallowed moves are computed
+// with one count per group.
+func (g *group) liberties() int {
+	b := g.places[0].board
+	if g.countedAt == b.version+1 {
+		return g.count
+	}
+	liberties := make(map[*place]nothing)
+	for _, p := range g.places {
+		for _, n := range p.neighbors() {
+			if n.color == Empty {
+				liberties[n] = nothing{}
+			}
+		}
+	}
+	g.count, g.countedAt = len(liberties), b.version+1
+	return g.count
+}
@@ -0,0 +1,60 @@
+package ggo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SetupHandicap puts count black stones on fixed handicap places as GTP
+// fixed_handicap command does, white moves first then.
+func (g *Game) SetupHandicap(count int) error {
+	places, err := handicapPlaces(g.Size(), count)
+	if err != nil {
+		return err
+	}
+	for _, p := range places {
+		if err := g.Setup(p[0], p[1], Black); err != nil {
+			return err
+		}
+	}
+	return g.SetMoveColor(White)
+}
+
+func handicapPlaces(size int, count int) ([][2]int, error) {
+	if size < 7 {
+		return nil, errors.New("board is too small for handicap")
+	}
+	max := 9
+	if size%2 == 0 || size == 7 {
+		max = 4
+	}
+	if count < 2 || count > max {
+		return nil, fmt.Errorf("handicap should be between 2 and %d", max)
+	}
+
+	edge := 3
+	if size < 13 {
+		edge = 2
+	}
+	near, far, middle := edge, size-1-edge, size/2
+
+	places := [][2]int{{far, near}, {near, far}, {near, near}, {far, far}}
+	switch count {
+	case 2, 3, 4:
+		return places[:count], nil
+	case 5:
+		return append(places, [2]int{middle, middle}), nil
+	}
+	places = append(places, [2]int{middle, near}, [2]int{middle, far})
+	switch count {
+	case 6:
+		return places, nil
+	case 7:
+		return append(places, [2]int{middle, middle}), nil
+	}
+	places = append(places, [2]int{far, middle}, [2]int{near, middle})
+	if count == 9 {
+		places = append(places, [2]int{middle, middle})
+	}
+	return places, nil
+}
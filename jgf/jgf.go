@@ -0,0 +1,467 @@
+// Package jgf reads and writes JGF (JSON Go Format) game records.
+//
+// JGF document is mapped onto the SGF game tree. Game information and
+// board size become root properties, "moves" is the main line starting
+// with the root node. Node with several children ends its sequence with
+// "variations", the first variation continues the main line. Places are
+// [x, y] from the top left corner, pass is []. SGF properties without JGF
+// counterpart are kept in node "properties".
+package jgf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/coord"
+	"github.com/someanon/ggo/sgf"
+)
+
+type document struct {
+	Record record `json:"record"`
+	Game   game   `json:"game"`
+	Board  board  `json:"board"`
+	Moves  []node `json:"moves"`
+}
+
+type record struct {
+	Application string `json:"application,omitempty"`
+	Version     int    `json:"version"`
+	Charset     string `json:"charset,omitempty"`
+}
+
+type game struct {
+	Type     string   `json:"type"`
+	Name     string   `json:"name,omitempty"`
+	Players  []player `json:"players,omitempty"`
+	Komi     *float64 `json:"komi,omitempty"`
+	Handicap int      `json:"handicap,omitempty"`
+	Result   string   `json:"result,omitempty"`
+	Date     string   `json:"date,omitempty"`
+	Event    string   `json:"event,omitempty"`
+	Place    string   `json:"place,omitempty"`
+	Time     *timing  `json:"time,omitempty"`
+}
+
+type player struct {
+	Color string `json:"color"`
+	Name  string `json:"name,omitempty"`
+	Rank  string `json:"rank,omitempty"`
+}
+
+type timing struct {
+	Main     float64 `json:"main"`
+	Overtime string  `json:"overtime,omitempty"`
+}
+
+type board struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type node struct {
+	Black      *point              `json:"B,omitempty"`
+	White      *point              `json:"W,omitempty"`
+	Setup      *setup              `json:"setup,omitempty"`
+	Comment    string              `json:"comment,omitempty"`
+	Properties map[string][]string `json:"properties,omitempty"`
+	Variations [][]node            `json:"variations,omitempty"`
+}
+
+type setup struct {
+	Black []point `json:"black,omitempty"`
+	White []point `json:"white,omitempty"`
+	Clear []point `json:"clear,omitempty"`
+}
+
+// point is [x, y], empty point is pass.
+type point []int
+
+// Read reads JGF document and replays its main line.
+func Read(r io.Reader) (*ggo.Game, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return sgf.ToGame(root)
+}
+
+// Write writes game as JGF document.
+func Write(w io.Writer, g *ggo.Game) error {
+	root, err := sgf.FromGame(g)
+	if err != nil {
+		return err
+	}
+	data, err := Marshal(root)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Marshal converts SGF game tree to JGF document.
+func Marshal(root *sgf.Node) ([]byte, error) {
+	size := 19
+	if v, ok := root.Value("SZ"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("jgf: invalid board size %q", v)
+		}
+		size = n
+	}
+
+	d := document{
+		Record: record{Version: 1},
+		Game:   game{Type: "go"},
+		Board:  board{Width: size, Height: size},
+	}
+	// Root properties which are mapped to document fields.
+	mapped := map[string]bool{"GM": true, "FF": true, "SZ": true}
+	value := func(id string) string {
+		mapped[id] = true
+		v, _ := root.Value(id)
+		return v
+	}
+
+	d.Record.Application = value("AP")
+	d.Record.Charset = value("CA")
+	d.Game.Name = value("GN")
+	d.Game.Result = value("RE")
+	d.Game.Date = value("DT")
+	d.Game.Event = value("EV")
+	d.Game.Place = value("PC")
+	for _, c := range []string{"B", "W"} {
+		name, rank := value("P"+c), value(c+"R")
+		if name != "" || rank != "" {
+			color := "black"
+			if c == "W" {
+				color = "white"
+			}
+			d.Game.Players = append(d.Game.Players, player{Color: color, Name: name, Rank: rank})
+		}
+	}
+	if v := value("KM"); v != "" {
+		komi, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("jgf: invalid komi %q", v)
+		}
+		d.Game.Komi = &komi
+	}
+	if v := value("HA"); v != "" {
+		handicap, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("jgf: invalid handicap %q", v)
+		}
+		d.Game.Handicap = handicap
+	}
+	if v := value("TM"); v != "" {
+		main, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("jgf: invalid time %q", v)
+		}
+		d.Game.Time = &timing{Main: main, Overtime: value("OT")}
+	}
+
+	var err error
+	d.Moves, err = marshalSequence(root, size, mapped)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func marshalSequence(n *sgf.Node, size int, skip map[string]bool) ([]node, error) {
+	var sequence []node
+	for {
+		jn, err := marshalNode(n, size, skip)
+		if err != nil {
+			return nil, err
+		}
+		skip = nil
+		if len(n.Children) > 1 {
+			for _, child := range n.Children {
+				variation, err := marshalSequence(child, size, nil)
+				if err != nil {
+					return nil, err
+				}
+				jn.Variations = append(jn.Variations, variation)
+			}
+		}
+		sequence = append(sequence, jn)
+		if len(n.Children) != 1 {
+			return sequence, nil
+		}
+		n = n.Children[0]
+	}
+}
+
+func marshalNode(n *sgf.Node, size int, skip map[string]bool) (node, error) {
+	var jn node
+	for _, p := range n.Properties {
+		if skip[p.ID] {
+			continue
+		}
+		var err error
+		if (p.ID == "B" || p.ID == "W" || p.ID == "C") && len(p.Values) != 1 {
+			return node{}, fmt.Errorf("jgf: property %s should have one value", p.ID)
+		}
+		switch p.ID {
+		case "B", "W":
+			var pt point
+			if pt, err = marshalPoint(p.Values[0], size); err == nil {
+				if p.ID == "B" {
+					jn.Black = &pt
+				} else {
+					jn.White = &pt
+				}
+			}
+		case "AB", "AW", "AE":
+			if jn.Setup == nil {
+				jn.Setup = &setup{}
+			}
+			var points []point
+			if points, err = marshalPoints(p.Values, size); err == nil {
+				switch p.ID {
+				case "AB":
+					jn.Setup.Black = points
+				case "AW":
+					jn.Setup.White = points
+				case "AE":
+					jn.Setup.Clear = points
+				}
+			}
+		case "C":
+			jn.Comment = p.Values[0]
+		default:
+			if jn.Properties == nil {
+				jn.Properties = make(map[string][]string)
+			}
+			jn.Properties[p.ID] = p.Values
+		}
+		if err != nil {
+			return node{}, fmt.Errorf("jgf: property %s: %v", p.ID, err)
+		}
+	}
+	return jn, nil
+}
+
+func marshalPoint(v string, size int) (point, error) {
+	if coord.IsPass(v, size, coord.SGF) {
+		return point{}, nil
+	}
+	row, column, err := coord.Parse(v, size, coord.SGF)
+	if err != nil {
+		return nil, err
+	}
+	return point{column, row}, nil
+}
+
+func marshalPoints(values []string, size int) ([]point, error) {
+	var points []point
+	for _, v := range values {
+		parts := strings.Split(v, ":")
+		if len(parts) == 2 {
+			from, err := marshalPoint(parts[0], size)
+			if err != nil {
+				return nil, err
+			}
+			to, err := marshalPoint(parts[1], size)
+			if err != nil {
+				return nil, err
+			}
+			if len(from) == 0 || len(to) == 0 {
+				return nil, fmt.Errorf("invalid point list %q", v)
+			}
+			for y := from[1]; y <= to[1]; y++ {
+				for x := from[0]; x <= to[0]; x++ {
+					points = append(points, point{x, y})
+				}
+			}
+			continue
+		}
+		p, err := marshalPoint(v, size)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// Unmarshal converts JGF document to SGF game tree.
+func Unmarshal(data []byte) (*sgf.Node, error) {
+	var d document
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("jgf: %v", err)
+	}
+	if d.Game.Type != "" && d.Game.Type != "go" {
+		return nil, fmt.Errorf("jgf: unsupported game type %q", d.Game.Type)
+	}
+	if d.Board.Width != d.Board.Height {
+		return nil, errors.New("jgf: board should be square")
+	}
+	size := 19
+	if d.Board.Width > 0 {
+		size = d.Board.Width
+	}
+	if size > coord.MaxSGFSize {
+		return nil, fmt.Errorf("jgf: too large board %dx%d", size, size)
+	}
+	if len(d.Moves) == 0 {
+		d.Moves = []node{{}}
+	}
+
+	root, err := unmarshalSequence(d.Moves, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// Document fields go before the root node own properties.
+	own := root.Properties
+	root.Properties = nil
+	set := func(id, value string) {
+		if value != "" {
+			root.Set(id, value)
+		}
+	}
+	set("GM", "1")
+	set("FF", "4")
+	set("CA", d.Record.Charset)
+	set("AP", d.Record.Application)
+	if d.Board.Width > 0 {
+		set("SZ", strconv.Itoa(d.Board.Width))
+	}
+	set("GN", d.Game.Name)
+	if d.Game.Komi != nil {
+		set("KM", strconv.FormatFloat(*d.Game.Komi, 'f', -1, 64))
+	}
+	if d.Game.Handicap > 0 {
+		set("HA", strconv.Itoa(d.Game.Handicap))
+	}
+	if d.Game.Time != nil {
+		set("TM", strconv.FormatFloat(d.Game.Time.Main, 'f', -1, 64))
+		set("OT", d.Game.Time.Overtime)
+	}
+	for _, p := range d.Game.Players {
+		switch p.Color {
+		case "black":
+			set("PB", p.Name)
+			set("BR", p.Rank)
+		case "white":
+			set("PW", p.Name)
+			set("WR", p.Rank)
+		default:
+			return nil, fmt.Errorf("jgf: unknown player color %q", p.Color)
+		}
+	}
+	set("DT", d.Game.Date)
+	set("EV", d.Game.Event)
+	set("PC", d.Game.Place)
+	set("RE", d.Game.Result)
+	root.Properties = append(root.Properties, own...)
+	return root, nil
+}
+
+func unmarshalSequence(sequence []node, size int) (*sgf.Node, error) {
+	var first, last *sgf.Node
+	for i, jn := range sequence {
+		n, err := unmarshalNode(jn, size)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = n
+		} else {
+			last.Children = append(last.Children, n)
+		}
+		last = n
+		if len(jn.Variations) > 0 {
+			if i != len(sequence)-1 {
+				return nil, errors.New("jgf: variations should end the sequence")
+			}
+			for _, v := range jn.Variations {
+				if len(v) == 0 {
+					return nil, errors.New("jgf: empty variation")
+				}
+				child, err := unmarshalSequence(v, size)
+				if err != nil {
+					return nil, err
+				}
+				last.Children = append(last.Children, child)
+			}
+		}
+	}
+	return first, nil
+}
+
+func unmarshalNode(jn node, size int) (*sgf.Node, error) {
+	n := &sgf.Node{}
+	for _, m := range []struct {
+		id string
+		p  *point
+	}{{"B", jn.Black}, {"W", jn.White}} {
+		if m.p == nil {
+			continue
+		}
+		v, err := unmarshalPoint(*m.p, size)
+		if err != nil {
+			return nil, err
+		}
+		n.Set(m.id, v)
+	}
+	if jn.Setup != nil {
+		for _, s := range []struct {
+			id     string
+			points []point
+		}{{"AB", jn.Setup.Black}, {"AW", jn.Setup.White}, {"AE", jn.Setup.Clear}} {
+			for _, p := range s.points {
+				v, err := unmarshalPoint(p, size)
+				if err != nil {
+					return nil, err
+				}
+				if v == "" {
+					return nil, errors.New("jgf: setup point shouldn't be empty")
+				}
+				n.Add(s.id, v)
+			}
+		}
+	}
+	ids := make([]string, 0, len(jn.Properties))
+	for id := range jn.Properties {
+		ids = append(ids, id)
+	}
+	// Map order is random, keep output stable.
+	sort.Strings(ids)
+	for _, id := range ids {
+		n.Set(id, jn.Properties[id]...)
+	}
+	if jn.Comment != "" {
+		n.Set("C", jn.Comment)
+	}
+	return n, nil
+}
+
+func unmarshalPoint(p point, size int) (string, error) {
+	switch len(p) {
+	case 0:
+		return "", nil
+	case 2:
+		v, err := coord.Format(p[1], p[0], size, coord.SGF)
+		if err != nil {
+			return "", fmt.Errorf("jgf: point %v: %v", []int(p), err)
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("jgf: invalid point %v", []int(p))
+}
@@ -0,0 +1,13 @@
+package jgf_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestJgf(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Jgf Suite")
+}
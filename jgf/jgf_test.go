@@ -0,0 +1,96 @@
+package jgf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/jgf"
+	"github.com/someanon/ggo/sgf"
+)
+
+var _ = Describe("JGF", func() {
+	const record = `(;GM[1]FF[4]CA[UTF-8]AP[ggo]SZ[9]KM[6.5]TM[600]OT[5x30 byo-yomi]
+		PB[black]BR[3k]PW[white]RE[W+R]AB[cc]AW[gg]PL[W]C[start]
+		;W[ee]C[center]
+		;B[]
+		(;W[ff];B[dd])
+		(;W[fe]MN[7]))`
+
+	parse := func(s string) *sgf.Node {
+		trees, err := sgf.Parse(strings.NewReader(s))
+		Expect(err).ToNot(HaveOccurred())
+		return trees[0]
+	}
+	sgfText := func(root *sgf.Node) string {
+		var buf bytes.Buffer
+		Expect(sgf.Write(&buf, root)).To(Succeed())
+		return buf.String()
+	}
+
+	It("writes metadata, setup, moves, comments and variations", func() {
+		data, err := Marshal(parse(record))
+		Expect(err).ToNot(HaveOccurred())
+		var d map[string]interface{}
+		Expect(json.Unmarshal(data, &d)).To(Succeed())
+		Expect(d["board"]).To(Equal(map[string]interface{}{"width": 9.0, "height": 9.0}))
+		game := d["game"].(map[string]interface{})
+		Expect(game["komi"]).To(Equal(6.5))
+		Expect(game["players"]).To(HaveLen(2))
+		moves := d["moves"].([]interface{})
+		Expect(moves).To(HaveLen(3))
+		Expect(moves[0].(map[string]interface{})["setup"]).To(Equal(map[string]interface{}{
+			"black": []interface{}{[]interface{}{2.0, 2.0}},
+			"white": []interface{}{[]interface{}{6.0, 6.0}},
+		}))
+		Expect(moves[1].(map[string]interface{})["comment"]).To(Equal("center"))
+		Expect(moves[2].(map[string]interface{})["B"]).To(Equal([]interface{}{}))
+		Expect(moves[2].(map[string]interface{})["variations"]).To(HaveLen(2))
+	})
+	It("keeps game tree through JGF", func() {
+		root := parse(record)
+		data, err := Marshal(root)
+		Expect(err).ToNot(HaveOccurred())
+		restored, err := Unmarshal(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sgfText(restored)).To(Equal(sgfText(root)))
+	})
+	It("keeps game through JGF", func() {
+		g := ggo.NewGame(ggo.Parameters{BoardSize: 9, Komi: 7})
+		g.SetInfo(ggo.Info{BlackName: "b", WhiteName: "w", Date: "2020-01-02"})
+		Expect(g.Play("E5", ggo.Black)).To(Succeed())
+		Expect(g.Play("pass", ggo.White)).To(Succeed())
+		var buf bytes.Buffer
+		Expect(Write(&buf, g)).To(Succeed())
+		restored, err := Read(&buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Parameters()).To(Equal(g.Parameters()))
+		Expect(restored.Info()).To(Equal(g.Info()))
+		Expect(restored.Moves()).To(Equal(g.Moves()))
+	})
+	It("rejects invalid documents", func() {
+		for _, s := range []string{
+			`{`,
+			`{"game": {"type": "chess"}}`,
+			`{"board": {"width": 9, "height": 13}}`,
+			`{"moves": [{"B": [1]}]}`,
+			`{"moves": [{"variations": [[{"B": [1, 1]}]]}, {"B": [2, 2]}]}`,
+			`{"board": {"width": 9, "height": 9}, "moves": [{"B": [9, 0]}]}`,
+			`{"board": {"width": 53, "height": 53}}`,
+		} {
+			_, err := Unmarshal([]byte(s))
+			Expect(err).To(HaveOccurred(), s)
+		}
+	})
+	It("rejects move and comment without single value", func() {
+		for _, p := range []sgf.Property{{ID: "B"}, {ID: "W", Values: []string{"aa", "bb"}}, {ID: "C"}} {
+			root := parse("(;SZ[9];B[aa])")
+			root.Children[0].Properties = []sgf.Property{p}
+			_, err := Marshal(root)
+			Expect(err).To(HaveOccurred(), p.ID)
+		}
+	})
+})
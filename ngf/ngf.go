@@ -0,0 +1,158 @@
+// Package ngf reads NGF game records of WBaduk.
+//
+// Record starts with twelve header lines: game name, board size, white
+// player, black player, site, handicap, unused, komi, date, unused,
+// result and moves count. Move lines are "PM", two letters of the move
+// number, color, and column and row letters from the top left corner
+// starting with B, other letters mean pass. Header carries no time settings, so imported games have no
+// time system.
+package ngf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/sgf"
+)
+
+const headerLines = 12
+
+// Read reads game record and replays it.
+func Read(r io.Reader) (*ggo.Game, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		lines = append(lines, strings.TrimSpace(s.Text()))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) < headerLines {
+		return nil, fmt.Errorf("ngf: header should have %d lines", headerLines)
+	}
+
+	size, err := strconv.Atoi(lines[1])
+	if err != nil || size < 1 || size > 25 {
+		return nil, fmt.Errorf("ngf: invalid board size %q", lines[1])
+	}
+	handicap, err := strconv.Atoi(lines[5])
+	if err != nil {
+		return nil, fmt.Errorf("ngf: invalid handicap %q", lines[5])
+	}
+	komi, err := strconv.ParseFloat(lines[7], 64)
+	if err != nil {
+		return nil, fmt.Errorf("ngf: invalid komi %q", lines[7])
+	}
+
+	g := ggo.NewGame(ggo.Parameters{BoardSize: size, Komi: komi})
+	whiteName, whiteRank := player(lines[2])
+	blackName, blackRank := player(lines[3])
+	g.SetInfo(ggo.Info{
+		BlackName: blackName,
+		BlackRank: blackRank,
+		WhiteName: whiteName,
+		WhiteRank: whiteRank,
+		Date:      date(lines[8]),
+		Event:     lines[0],
+		Place:     lines[4],
+		Result:    result(lines[10]),
+	})
+	if handicap > 1 {
+		if err := g.SetupHandicap(handicap); err != nil {
+			return nil, fmt.Errorf("ngf: %v", err)
+		}
+	}
+
+	for _, line := range lines[headerLines:] {
+		if !strings.HasPrefix(line, "PM") {
+			continue
+		}
+		if len(line) < 7 {
+			return nil, fmt.Errorf("ngf: invalid move line %q", line)
+		}
+		var color ggo.Color
+		switch line[4] {
+		case 'B':
+			color = ggo.Black
+		case 'W':
+			color = ggo.White
+		default:
+			return nil, fmt.Errorf("ngf: invalid color in line %q", line)
+		}
+		if len(g.Moves()) == 0 && g.MoveColor() != color {
+			if err := g.SetMoveColor(color); err != nil {
+				return nil, fmt.Errorf("ngf: %v", err)
+			}
+		}
+		column, row := int(line[5])-'B', int(line[6])-'B'
+		if row >= 0 && column >= 0 && row < size && column < size {
+			err = g.Move(row, column, color)
+		} else {
+			err = g.Pass(color)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ngf: move %d: %v", len(g.Moves())+1, err)
+		}
+	}
+	return g, nil
+}
+
+// ToSGF converts game record to SGF.
+func ToSGF(r io.Reader, w io.Writer) error {
+	return sgf.Convert(Read, r, w)
+}
+
+// player splits "name      3D*" line.
+func player(v string) (string, string) {
+	fields := strings.Fields(v)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return fields[0], ""
+	}
+	rank := strings.TrimRight(fields[len(fields)-1], "*")
+	return strings.Join(fields[:len(fields)-1], " "), rank
+}
+
+// date converts "20040926 [19:43]" to "2004-09-26".
+func date(v string) string {
+	fields := strings.Fields(v)
+	if len(fields) == 0 || len(fields[0]) != 8 {
+		return v
+	}
+	d := fields[0]
+	return d[:4] + "-" + d[4:6] + "-" + d[6:]
+}
+
+var scorePattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// result converts "White wins by resign!" or "Black wins by 3.5!" to SGF
+// form.
+func result(v string) string {
+	lower := strings.ToLower(v)
+	var winner string
+	switch {
+	case strings.HasPrefix(lower, "white"):
+		winner = "W"
+	case strings.HasPrefix(lower, "black"):
+		winner = "B"
+	default:
+		return ""
+	}
+	switch {
+	case strings.Contains(lower, "resign"):
+		return winner + "+R"
+	case strings.Contains(lower, "time"):
+		return winner + "+T"
+	}
+	if score := scorePattern.FindString(lower); score != "" {
+		return winner + "+" + score
+	}
+	return winner + "+"
+}
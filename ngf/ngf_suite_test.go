@@ -0,0 +1,13 @@
+package ngf_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestNgf(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ngf Suite")
+}
@@ -0,0 +1,67 @@
+package ngf_test
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/ngf"
+	"github.com/someanon/ggo/sgf"
+)
+
+const record = `Rated game
+9
+white      2D*
+black      1K
+http://www.wbaduk.com/
+0
+0
+5
+20040926 [19:43]
+0
+Black wins by 3.5!
+4
+PMABBFFFF
+PMACWDDDD
+PMADBAAAA
+PMAEWDEDE
+`
+
+var _ = Describe("NGF", func() {
+	It("reads game", func() {
+		g, err := Read(strings.NewReader(record))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.Size()).To(Equal(9))
+		Expect(g.Parameters().Komi).To(Equal(5.0))
+		Expect(g.Info()).To(Equal(ggo.Info{
+			BlackName: "black",
+			BlackRank: "1K",
+			WhiteName: "white",
+			WhiteRank: "2D",
+			Date:      "2004-09-26",
+			Event:     "Rated game",
+			Place:     "http://www.wbaduk.com/",
+			Result:    "B+3.5",
+		}))
+		Expect(g.Moves()).To(Equal([]ggo.Move{
+			{Color: ggo.Black, Row: 4, Column: 4},
+			{Color: ggo.White, Row: 2, Column: 2},
+			{Color: ggo.Black, Pass: true},
+			{Color: ggo.White, Row: 3, Column: 2},
+		}))
+	})
+	It("converts game to SGF", func() {
+		var buf bytes.Buffer
+		Expect(ToSGF(strings.NewReader(record), &buf)).To(Succeed())
+		trees, err := sgf.Parse(&buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(trees[0].Values("PW")).To(Equal([]string{"white"}))
+		Expect(trees[0].Values("RE")).To(Equal([]string{"B+3.5"}))
+	})
+	It("rejects short header", func() {
+		_, err := Read(strings.NewReader("game\n19\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,41 @@
+package ggo
+
+import (
+	"fmt"
+)
+
+type Phase byte
+
+const (
+	Playing Phase = iota
+	Over
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Playing:
+		return "playing"
+	case Over:
+		return "over"
+	}
+	return fmt.Sprintf("Phase(%d)", byte(p))
+}
+
+func (p Phase) MarshalText() ([]byte, error) {
+	if p > Over {
+		return nil, fmt.Errorf("unknown phase %d", byte(p))
+	}
+	return []byte(p.String()), nil
+}
+
+func (p *Phase) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "playing":
+		*p = Playing
+	case "over":
+		*p = Over
+	default:
+		return fmt.Errorf("unknown phase %q", text)
+	}
+	return nil
+}
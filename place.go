@@ -68,7 +68,7 @@ func (p *place) analyzeNeighbors(color Color) (int, []*group, []*group) {
 
 	dyingEnemyGroups := make([]*group, 0)
 	for eg := range enemyGroupsMap {
-		if eg.liberties == 1 {
+		if eg.liberties() == 1 {
 			dyingEnemyGroups = append(dyingEnemyGroups, eg)
 		}
 	}
@@ -81,26 +81,26 @@ func (p *place) analyzeNeighbors(color Color) (int, []*group, []*group) {
 	return len(libertiesMap), friendGroups, dyingEnemyGroups
 }
 
-func (p *place) put(color Color) error {
+func (p *place) put(color Color) ([]*place, error) {
 	if color == Empty {
-		return errors.New("color shouldn't be empty")
+		return nil, errors.New("color shouldn't be empty")
 	}
 	if p.color != Empty {
-		return errors.New("already occupied")
+		return nil, errors.New("already occupied")
 	}
 
 	libertiesCount, friendGroups, dyingEnemyGroups := p.analyzeNeighbors(color)
 
 	if libertiesCount == 0 && len(dyingEnemyGroups) == 0 {
-		return errors.New("no liberties and no neighbor enemy group is dying")
+		return nil, errors.New("no liberties and no neighbor enemy group is dying")
 	}
 
 	p.color = color
+	p.board.version++
 
 	if len(friendGroups) == 0 {
 		p.group = &group{
-			places:    []*place{p},
-			liberties: libertiesCount + len(dyingEnemyGroups),
+			places: []*place{p},
 		}
 	} else {
 		var baseGroup *group
@@ -112,10 +112,12 @@ func (p *place) put(color Color) error {
 			baseGroup.join(fg)
 		}
 		baseGroup.places = append(baseGroup.places, p)
-		baseGroup.liberties = libertiesCount
+		p.group = baseGroup
 	}
 
+	captured := make([]*place, 0)
 	for _, eg := range dyingEnemyGroups {
+		captured = append(captured, eg.places...)
 		eg.die()
 	}
 
@@ -126,19 +128,11 @@ func (p *place) put(color Color) error {
 		p.board.koPlace = nil
 	}
 
-	return nil
+	return captured, nil
 }
 
 func (p *place) die() {
-	enemyGroups := make(map[*group]struct{})
-	for _, n := range p.neighbors() {
-		if n.color != Empty && n.color != p.color {
-			enemyGroups[n.group] = nothing{}
-		}
-	}
-	for eg := range enemyGroups {
-		eg.liberties++
-	}
 	p.group = nil
 	p.color = Empty
+	p.board.version++
 }
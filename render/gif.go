@@ -0,0 +1,92 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/someanon/ggo"
+)
+
+// GIF draws animated replay of the game: the initial position and one
+// frame per move, each shown for delay. Markup is drawn on the last
+// frame only.
+func GIF(w io.Writer, g *ggo.Game, options Options, delay time.Duration) error {
+	if err := validate(g, options); err != nil {
+		return err
+	}
+	if delay < 0 {
+		return errors.New("delay should be greater or equal to zero")
+	}
+	options = withDefaults(options)
+
+	replay, err := g.AfterMoves(0)
+	if err != nil {
+		return err
+	}
+
+	frameOptions := options
+	frameOptions.Markup = Markup{}
+	palette := themePalette(options.Theme)
+	bounds := image.Rect(0, 0, options.Size, options.Size)
+	moves := g.Moves()
+	anim := &gif.GIF{}
+
+	for i := 0; i <= len(moves); i++ {
+		if i > 0 {
+			m := moves[i-1]
+			if m.Pass {
+				err = replay.Pass(m.Color)
+			} else {
+				err = replay.Move(m.Row, m.Column, m.Color)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		o := frameOptions
+		if i == len(moves) {
+			o = options
+		}
+		img := image.NewRGBA(bounds)
+		paint(&rasterPainter{img: img}, replay, o)
+		frame := image.NewPaletted(bounds, palette)
+		draw.Draw(frame, bounds, img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, int(delay/(10*time.Millisecond)))
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// themePalette returns theme colors and blends between each pair of
+// them, so antialiased edges keep smooth in paletted image.
+func themePalette(t Theme) color.Palette {
+	const steps = 24
+	base := []color.RGBA{t.Background, t.Grid, t.Black, t.White, t.Markup}
+	seen := make(map[color.RGBA]bool)
+	palette := make(color.Palette, 0, 256)
+	for i, a := range base {
+		for _, b := range base[i+1:] {
+			for s := 0; s <= steps; s++ {
+				c := mix(a, b, float64(s)/steps)
+				if !seen[c] && len(palette) < 256 {
+					seen[c] = true
+					palette = append(palette, c)
+				}
+			}
+		}
+	}
+	return palette
+}
+
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	m := func(x, y uint8) uint8 {
+		return uint8(float64(x)*(1-t) + float64(y)*t + 0.5)
+	}
+	return color.RGBA{R: m(a.R, b.R), G: m(a.G, b.G), B: m(a.B, b.B), A: 0xff}
+}
@@ -0,0 +1,163 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/someanon/ggo"
+)
+
+// Image draws the game position as raster image.
+func Image(g *ggo.Game, options Options) (*image.RGBA, error) {
+	if err := validate(g, options); err != nil {
+		return nil, err
+	}
+	options = withDefaults(options)
+	img := image.NewRGBA(image.Rect(0, 0, options.Size, options.Size))
+	paint(&rasterPainter{img: img}, g, options)
+	return img, nil
+}
+
+// PNG draws the game position as PNG image.
+func PNG(w io.Writer, g *ggo.Game, options Options) error {
+	img, err := Image(g, options)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// rasterPainter draws antialiased shapes by computing how much of every
+// pixel the shape covers.
+type rasterPainter struct {
+	img *image.RGBA
+}
+
+func (r *rasterPainter) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	// Square line cap extends the line by half of the width.
+	length := math.Hypot(x2-x1, y2-y1)
+	if length > 0 {
+		dx, dy := (x2-x1)/length*width/2, (y2-y1)/length*width/2
+		x1, y1, x2, y2 = x1-dx, y1-dy, x2+dx, y2+dy
+	}
+	r.segment(x1, y1, x2, y2, width, stroke)
+}
+
+func (r *rasterPainter) segment(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	pad := width/2 + 1
+	r.each(math.Min(x1, x2)-pad, math.Min(y1, y2)-pad, math.Max(x1, x2)+pad,
+		math.Max(y1, y2)+pad, stroke, func(x, y float64) float64 {
+			return width/2 - distanceToSegment(x, y, x1, y1, x2, y2) + 0.5
+		})
+}
+
+func (r *rasterPainter) rect(x, y, width, height float64, fill color.RGBA) {
+	r.each(x, y, x+width, y+height, fill, func(px, py float64) float64 {
+		return overlap(px-0.5, px+0.5, x, x+width) * overlap(py-0.5, py+0.5, y, y+height)
+	})
+}
+
+func (r *rasterPainter) circle(cx, cy, radius float64, fill color.RGBA, stroke color.RGBA,
+	strokeWidth float64) {
+	pad := radius + strokeWidth/2 + 1
+	if fill.A > 0 {
+		r.each(cx-pad, cy-pad, cx+pad, cy+pad, fill, func(x, y float64) float64 {
+			return radius - math.Hypot(x-cx, y-cy) + 0.5
+		})
+	}
+	if strokeWidth > 0 {
+		r.each(cx-pad, cy-pad, cx+pad, cy+pad, stroke, func(x, y float64) float64 {
+			return strokeWidth/2 - math.Abs(math.Hypot(x-cx, y-cy)-radius) + 0.5
+		})
+	}
+}
+
+func (r *rasterPainter) triangle(cx, cy, radius float64, stroke color.RGBA, strokeWidth float64) {
+	xs := []float64{cx, cx - radius*0.87, cx + radius*0.87}
+	ys := []float64{cy - radius, cy + radius/2, cy + radius/2}
+	for i := range xs {
+		j := (i + 1) % len(xs)
+		r.segment(xs[i], ys[i], xs[j], ys[j], strokeWidth, stroke)
+	}
+}
+
+// text draws text with bitmap font scaled by whole pixels. Text is
+// scaled down until it is at most twice as wide as the font size.
+func (r *rasterPainter) text(cx, cy, size float64, text string, fill color.RGBA) {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return
+	}
+	textWidth := func(scale int) int {
+		return (len(runes)*(glyphWidth+1) - 1) * scale
+	}
+	scale := int(math.Round(size * 0.7 / glyphHeight))
+	for scale > 1 && float64(textWidth(scale)) > size*2 {
+		scale--
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	left := int(math.Round(cx - float64(textWidth(scale))/2))
+	top := int(math.Round(cy - float64(glyphHeight*scale)/2))
+	for i, ch := range runes {
+		g := glyph(ch)
+		for row := 0; row < glyphHeight; row++ {
+			for column := 0; column < glyphWidth; column++ {
+				if g[row][column] != '#' {
+					continue
+				}
+				x := left + (i*(glyphWidth+1)+column)*scale
+				y := top + row*scale
+				r.rect(float64(x), float64(y), float64(scale), float64(scale), fill)
+			}
+		}
+	}
+}
+
+// each blends color into pixels of the bounding box with coverage
+// computed at pixel centers.
+func (r *rasterPainter) each(x1, y1, x2, y2 float64, c color.RGBA,
+	coverage func(x, y float64) float64) {
+	bounds := image.Rect(int(math.Floor(x1)), int(math.Floor(y1)),
+		int(math.Ceil(x2)), int(math.Ceil(y2))).Intersect(r.img.Rect)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			a := math.Min(math.Max(coverage(float64(x)+0.5, float64(y)+0.5), 0), 1)
+			r.blend(x, y, c, a)
+		}
+	}
+}
+
+func (r *rasterPainter) blend(x, y int, c color.RGBA, coverage float64) {
+	a := coverage * float64(c.A) / 0xff
+	if a <= 0 {
+		return
+	}
+	i := r.img.PixOffset(x, y)
+	pix := r.img.Pix[i : i+4]
+	mix := func(dst uint8, src uint8) uint8 {
+		return uint8(float64(src)*a + float64(dst)*(1-a) + 0.5)
+	}
+	pix[0] = mix(pix[0], c.R)
+	pix[1] = mix(pix[1], c.G)
+	pix[2] = mix(pix[2], c.B)
+	pix[3] = mix(pix[3], 0xff)
+}
+
+func overlap(a1, a2, b1, b2 float64) float64 {
+	return math.Max(0, math.Min(a2, b2)-math.Max(a1, b1))
+}
+
+func distanceToSegment(x, y, x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return math.Hypot(x-x1, y-y1)
+	}
+	t := math.Min(math.Max(((x-x1)*dx+(y-y1)*dy)/lengthSquared, 0), 1)
+	return math.Hypot(x-(x1+t*dx), y-(y1+t*dy))
+}
@@ -0,0 +1,63 @@
+package render_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/render"
+)
+
+var _ = Describe("Raster", func() {
+	var g *ggo.Game
+	BeforeEach(func() {
+		g = ggo.NewGame(ggo.Parameters{BoardSize: 9})
+		Expect(g.Move(2, 2, ggo.Black)).To(Succeed())
+		Expect(g.Move(6, 6, ggo.White)).To(Succeed())
+		Expect(g.Pass(ggo.Black)).To(Succeed())
+	})
+	// at returns color of the image pixel near the center of the place,
+	// but off the grid lines.
+	at := func(img interface {
+		At(x, y int) color.Color
+	}, row, column int) color.RGBA {
+		unit := 180 / 9
+		r, g, b, a := img.At(column*unit+unit/2+3, row*unit+unit/2+3).RGBA()
+		return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	}
+	Describe("PNG", func() {
+		It("draws stones", func() {
+			var buf bytes.Buffer
+			Expect(PNG(&buf, g, Options{Size: 180})).To(Succeed())
+			img, err := png.Decode(&buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(img.Bounds().Dx()).To(Equal(180))
+			Expect(at(img, 2, 2)).To(Equal(WoodTheme.Black))
+			Expect(at(img, 6, 6)).To(Equal(WoodTheme.White))
+			Expect(at(img, 0, 0)).To(Equal(WoodTheme.Background))
+		})
+		It("rejects zero size", func() {
+			var buf bytes.Buffer
+			Expect(PNG(&buf, g, Options{})).ToNot(Succeed())
+		})
+	})
+	Describe("GIF", func() {
+		It("draws frame per move", func() {
+			var buf bytes.Buffer
+			Expect(GIF(&buf, g, Options{Size: 180}, 750*time.Millisecond)).To(Succeed())
+			anim, err := gif.DecodeAll(&buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(anim.Image).To(HaveLen(4))
+			Expect(anim.Delay).To(Equal([]int{75, 75, 75, 75}))
+			Expect(at(anim.Image[0], 2, 2)).To(Equal(WoodTheme.Background))
+			Expect(at(anim.Image[1], 2, 2)).To(Equal(WoodTheme.Black))
+			Expect(at(anim.Image[1], 6, 6)).To(Equal(WoodTheme.Background))
+			Expect(at(anim.Image[2], 6, 6)).To(Equal(WoodTheme.White))
+		})
+	})
+})
@@ -0,0 +1,308 @@
+package render
+
+import (
+	"errors"
+	"image/color"
+	"sort"
+	"strconv"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/coord"
+)
+
+type Point struct {
+	Row    int
+	Column int
+}
+
+type Theme struct {
+	Background color.RGBA
+	Grid       color.RGBA
+	Black      color.RGBA
+	White      color.RGBA
+	Markup     color.RGBA
+}
+
+var (
+	WoodTheme = Theme{
+		Background: color.RGBA{0xdc, 0xb3, 0x5c, 0xff},
+		Grid:       color.RGBA{0x00, 0x00, 0x00, 0xff},
+		Black:      color.RGBA{0x00, 0x00, 0x00, 0xff},
+		White:      color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Markup:     color.RGBA{0xd0, 0x20, 0x20, 0xff},
+	}
+	BookTheme = Theme{
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Grid:       color.RGBA{0x40, 0x40, 0x40, 0xff},
+		Black:      color.RGBA{0x00, 0x00, 0x00, 0xff},
+		White:      color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Markup:     color.RGBA{0x00, 0x00, 0x00, 0xff},
+	}
+)
+
+// Markup is additional drawing over the position.
+type Markup struct {
+	Territory map[Point]ggo.Color
+	Labels    map[Point]string
+	Triangles []Point
+}
+
+type Options struct {
+	// Size is width and height of the image in pixels.
+	Size        int
+	Theme       Theme
+	Coordinates bool
+	LastMove    bool
+	MoveNumbers bool
+	Markup      Markup
+}
+
+// position is everything needed to draw a board.
+type position struct {
+	size     int
+	stones   [][]ggo.Color
+	numbers  map[Point]int
+	lastMove *Point
+}
+
+func newPosition(g *ggo.Game) position {
+	p := position{
+		size:    g.Size(),
+		stones:  g.Position(),
+		numbers: make(map[Point]int),
+	}
+	for i, m := range g.Moves() {
+		if m.Pass {
+			p.lastMove = nil
+			continue
+		}
+		pt := Point{Row: m.Row, Column: m.Column}
+		p.numbers[pt] = i + 1
+		p.lastMove = &pt
+	}
+	for pt := range p.numbers {
+		if p.stones[pt.Row][pt.Column] == ggo.Empty {
+			delete(p.numbers, pt)
+		}
+	}
+	return p
+}
+
+// layout maps board places to image coordinates.
+type layout struct {
+	unit   float64
+	origin float64
+}
+
+func newLayout(boardSize int, options Options) layout {
+	if options.Coordinates {
+		unit := float64(options.Size) / float64(boardSize+1)
+		return layout{unit: unit, origin: unit}
+	}
+	unit := float64(options.Size) / float64(boardSize)
+	return layout{unit: unit, origin: unit / 2}
+}
+
+func (l layout) x(column int) float64 {
+	return l.origin + float64(column)*l.unit
+}
+
+func (l layout) y(row int) float64 {
+	return l.origin + float64(row)*l.unit
+}
+
+func validate(g *ggo.Game, options Options) error {
+	if options.Size < 1 {
+		return errors.New("size should be greater than zero")
+	}
+	if g.Size() > coord.MaxGTPSize && options.Coordinates {
+		return errors.New("too large board for coordinates")
+	}
+	inside := func(p Point) bool {
+		return p.Row >= 0 && p.Column >= 0 && p.Row < g.Size() && p.Column < g.Size()
+	}
+	for p := range options.Markup.Territory {
+		if !inside(p) {
+			return errors.New("territory point is out of board")
+		}
+	}
+	for p := range options.Markup.Labels {
+		if !inside(p) {
+			return errors.New("label point is out of board")
+		}
+	}
+	for _, p := range options.Markup.Triangles {
+		if !inside(p) {
+			return errors.New("triangle point is out of board")
+		}
+	}
+	return nil
+}
+
+// starPoints returns hoshi places for the board size.
+func starPoints(size int) []Point {
+	var edge int
+	switch {
+	case size >= 13:
+		edge = 3
+	case size >= 7:
+		edge = 2
+	default:
+		return nil
+	}
+	lines := []int{edge, size - 1 - edge}
+	points := make([]Point, 0, 9)
+	for _, r := range lines {
+		for _, c := range lines {
+			points = append(points, Point{Row: r, Column: c})
+		}
+	}
+	if size%2 == 1 {
+		middle := size / 2
+		if size >= 15 {
+			for _, l := range lines {
+				points = append(points,
+					Point{Row: l, Column: middle}, Point{Row: middle, Column: l})
+			}
+		}
+		points = append(points, Point{Row: middle, Column: middle})
+	}
+	return points
+}
+
+// contrast returns color for markup drawn over the place.
+func (t Theme) contrast(stone ggo.Color) color.RGBA {
+	switch stone {
+	case ggo.Black:
+		return t.White
+	case ggo.White:
+		return t.Black
+	}
+	return t.Markup
+}
+
+func (t Theme) stone(stone ggo.Color) color.RGBA {
+	if stone == ggo.Black {
+		return t.Black
+	}
+	return t.White
+}
+
+// painter is drawing backend, e.g. SVG or raster image. Transparent
+// fill or zero stroke width means no fill or no stroke.
+type painter interface {
+	line(x1, y1, x2, y2, width float64, stroke color.RGBA)
+	rect(x, y, width, height float64, fill color.RGBA)
+	circle(cx, cy, r float64, fill color.RGBA, stroke color.RGBA, strokeWidth float64)
+	triangle(cx, cy, r float64, stroke color.RGBA, strokeWidth float64)
+	text(cx, cy, size float64, text string, fill color.RGBA)
+}
+
+func paint(pr painter, g *ggo.Game, options Options) {
+	p := newPosition(g)
+	l := newLayout(p.size, options)
+	t := options.Theme
+
+	size := float64(options.Size)
+	pr.rect(0, 0, size, size, t.Background)
+
+	first, last := l.x(0), l.x(p.size-1)
+	stroke := l.unit / 25
+	for i := 0; i < p.size; i++ {
+		pr.line(first, l.y(i), last, l.y(i), stroke, t.Grid)
+		pr.line(l.x(i), first, l.x(i), last, stroke, t.Grid)
+	}
+
+	for _, sp := range starPoints(p.size) {
+		pr.circle(l.x(sp.Column), l.y(sp.Row), l.unit/10, t.Grid, color.RGBA{}, 0)
+	}
+
+	if options.Coordinates {
+		near, far := l.origin-l.unit*0.6, l.x(p.size-1)+l.unit*0.6
+		for i := 0; i < p.size; i++ {
+			letter := coord.GTPColumn(i)
+			number := strconv.Itoa(p.size - i)
+			pr.text(l.x(i), near, l.unit*0.4, letter, t.Grid)
+			pr.text(l.x(i), far, l.unit*0.4, letter, t.Grid)
+			pr.text(near, l.y(i), l.unit*0.4, number, t.Grid)
+			pr.text(far, l.y(i), l.unit*0.4, number, t.Grid)
+		}
+	}
+
+	// Territory is drawn under the stones, so dead stones stay visible.
+	territory := make([]Point, 0, len(options.Markup.Territory))
+	for pt := range options.Markup.Territory {
+		territory = append(territory, pt)
+	}
+	for _, pt := range sortPoints(territory) {
+		side := l.unit * 0.4
+		fill := t.stone(options.Markup.Territory[pt])
+		fill.A = 0xcc
+		pr.rect(l.x(pt.Column)-side/2, l.y(pt.Row)-side/2, side, side, fill)
+	}
+
+	for r := 0; r < p.size; r++ {
+		for c := 0; c < p.size; c++ {
+			stone := p.stones[r][c]
+			if stone == ggo.Empty {
+				continue
+			}
+			pr.circle(l.x(c), l.y(r), l.unit*0.48-stroke/2, t.stone(stone), t.Black, stroke)
+		}
+	}
+
+	if options.MoveNumbers {
+		numbered := make([]Point, 0, len(p.numbers))
+		for pt := range p.numbers {
+			numbered = append(numbered, pt)
+		}
+		for _, pt := range sortPoints(numbered) {
+			fill := t.contrast(p.stones[pt.Row][pt.Column])
+			if options.LastMove && p.lastMove != nil && *p.lastMove == pt {
+				fill = t.Markup
+			}
+			pr.text(l.x(pt.Column), l.y(pt.Row), l.unit*0.45, strconv.Itoa(p.numbers[pt]), fill)
+		}
+	} else if options.LastMove && p.lastMove != nil {
+		pt := *p.lastMove
+		pr.circle(l.x(pt.Column), l.y(pt.Row), l.unit*0.25, color.RGBA{},
+			t.contrast(p.stones[pt.Row][pt.Column]), stroke*2)
+	}
+
+	for _, pt := range options.Markup.Triangles {
+		pr.triangle(l.x(pt.Column), l.y(pt.Row), l.unit*0.3,
+			t.contrast(p.stones[pt.Row][pt.Column]), stroke*2)
+	}
+
+	labeled := make([]Point, 0, len(options.Markup.Labels))
+	for pt := range options.Markup.Labels {
+		labeled = append(labeled, pt)
+	}
+	for _, pt := range sortPoints(labeled) {
+		x, y := l.x(pt.Column), l.y(pt.Row)
+		stone := p.stones[pt.Row][pt.Column]
+		if stone == ggo.Empty {
+			// Hide grid lines under the label.
+			pr.rect(x-l.unit*0.3, y-l.unit*0.3, l.unit*0.6, l.unit*0.6, t.Background)
+		}
+		pr.text(x, y, l.unit*0.5, options.Markup.Labels[pt], t.contrast(stone))
+	}
+}
+
+func withDefaults(options Options) Options {
+	if options.Theme == (Theme{}) {
+		options.Theme = WoodTheme
+	}
+	return options
+}
+
+// sortPoints sorts points in row-major order, so output is stable.
+func sortPoints(points []Point) []Point {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Row != points[j].Row {
+			return points[i].Row < points[j].Row
+		}
+		return points[i].Column < points[j].Column
+	})
+	return points
+}
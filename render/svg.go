@@ -0,0 +1,104 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/someanon/ggo"
+)
+
+// SVG draws the game position as SVG image.
+func SVG(w io.Writer, g *ggo.Game, options Options) error {
+	if err := validate(g, options); err != nil {
+		return err
+	}
+	options = withDefaults(options)
+
+	bw := bufio.NewWriter(w)
+	s := &svgPainter{w: bw}
+
+	s.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		options.Size, options.Size, options.Size, options.Size)
+	paint(s, g, options)
+	s.printf("</svg>\n")
+
+	if s.err != nil {
+		return s.err
+	}
+	return bw.Flush()
+}
+
+// svgPainter writes shapes as SVG elements. It remembers the first write
+// error, so drawing code doesn't have to check every write.
+type svgPainter struct {
+	w   io.Writer
+	err error
+}
+
+func (s *svgPainter) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	s.printf(`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke-linecap="square"%s/>`+"\n",
+		num(x1), num(y1), num(x2), num(y2), strokeAttrs(stroke, width))
+}
+
+func (s *svgPainter) rect(x, y, width, height float64, fill color.RGBA) {
+	s.printf(`<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n",
+		num(x), num(y), num(width), num(height), fillAttrs(fill))
+}
+
+func (s *svgPainter) circle(cx, cy, r float64, fill color.RGBA, stroke color.RGBA,
+	strokeWidth float64) {
+	s.printf(`<circle cx="%s" cy="%s" r="%s"%s%s/>`+"\n",
+		num(cx), num(cy), num(r), fillAttrs(fill), strokeAttrs(stroke, strokeWidth))
+}
+
+func (s *svgPainter) triangle(cx, cy, r float64, stroke color.RGBA, strokeWidth float64) {
+	s.printf(`<polygon points="%s,%s %s,%s %s,%s"%s%s/>`+"\n",
+		num(cx), num(cy-r), num(cx-r*0.87), num(cy+r/2), num(cx+r*0.87), num(cy+r/2),
+		fillAttrs(color.RGBA{}), strokeAttrs(stroke, strokeWidth))
+}
+
+func (s *svgPainter) text(cx, cy, size float64, text string, fill color.RGBA) {
+	s.printf(`<text x="%s" y="%s" font-family="sans-serif" font-size="%s" text-anchor="middle" dominant-baseline="central"%s>`,
+		num(cx), num(cy), num(size), fillAttrs(fill))
+	if s.err == nil {
+		s.err = xml.EscapeText(s.w, []byte(text))
+	}
+	s.printf("</text>\n")
+}
+
+func (s *svgPainter) printf(format string, args ...interface{}) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func fillAttrs(c color.RGBA) string {
+	if c.A == 0 {
+		return ` fill="none"`
+	}
+	if c.A < 0xff {
+		return fmt.Sprintf(` fill="%s" fill-opacity="%s"`, hex(c), num(float64(c.A)/0xff))
+	}
+	return fmt.Sprintf(` fill="%s"`, hex(c))
+}
+
+func strokeAttrs(c color.RGBA, width float64) string {
+	if width == 0 {
+		return ""
+	}
+	return fmt.Sprintf(` stroke="%s" stroke-width="%s"`, hex(c), num(width))
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,103 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/render"
+)
+
+var _ = Describe("SVG", func() {
+	var g *ggo.Game
+	BeforeEach(func() {
+		var err error
+		g, err = ggo.ParseGame(`
+			. . . . . . . . .
+			. . . . . . . . .
+			. . X . . . O . .
+			. . . . . . . . .
+			. . . . . . . . .
+			. . . . . . . . .
+			. . . . . . . . .
+			. . . . . . . . .
+			. . . . . . . . .
+		`, ggo.Black)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.Move(4, 4, ggo.Black)).To(Succeed())
+		Expect(g.Move(6, 6, ggo.White)).To(Succeed())
+	})
+	// elements counts SVG elements by name and collects text contents.
+	elements := func(data []byte) (map[string]int, []string) {
+		counts := make(map[string]int)
+		var texts []string
+		d := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			token, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			switch t := token.(type) {
+			case xml.StartElement:
+				counts[t.Name.Local]++
+			case xml.CharData:
+				if text := strings.TrimSpace(string(t)); text != "" {
+					texts = append(texts, text)
+				}
+			}
+		}
+		return counts, texts
+	}
+	It("draws grid, star points and stones", func() {
+		var buf bytes.Buffer
+		Expect(SVG(&buf, g, Options{Size: 300})).To(Succeed())
+		counts, texts := elements(buf.Bytes())
+		Expect(counts["svg"]).To(Equal(1))
+		Expect(counts["line"]).To(Equal(18))
+		Expect(counts["circle"]).To(Equal(5 + 4))
+		Expect(texts).To(BeEmpty())
+	})
+	It("draws coordinates and move numbers", func() {
+		var buf bytes.Buffer
+		Expect(SVG(&buf, g, Options{Size: 300, Coordinates: true, MoveNumbers: true})).To(Succeed())
+		_, texts := elements(buf.Bytes())
+		Expect(texts).To(HaveLen(9*4 + 2))
+		Expect(texts).To(ContainElement("J"))
+		Expect(texts).ToNot(ContainElement("I"))
+		Expect(texts).To(ContainElement("1"))
+		Expect(texts).To(ContainElement("2"))
+	})
+	It("marks last move", func() {
+		var buf bytes.Buffer
+		Expect(SVG(&buf, g, Options{Size: 300, LastMove: true})).To(Succeed())
+		counts, _ := elements(buf.Bytes())
+		Expect(counts["circle"]).To(Equal(5 + 4 + 1))
+	})
+	It("draws markup", func() {
+		var buf bytes.Buffer
+		Expect(SVG(&buf, g, Options{
+			Size:  300,
+			Theme: BookTheme,
+			Markup: Markup{
+				Territory: map[Point]ggo.Color{{0, 0}: ggo.Black, {8, 8}: ggo.White},
+				Labels:    map[Point]string{{1, 1}: "A", {2, 2}: "<b>"},
+				Triangles: []Point{{6, 6}},
+			},
+		})).To(Succeed())
+		counts, texts := elements(buf.Bytes())
+		Expect(counts["rect"]).To(Equal(1 + 2 + 1))
+		Expect(counts["polygon"]).To(Equal(1))
+		Expect(texts).To(Equal([]string{"A", "<b>"}))
+	})
+	It("rejects markup out of board", func() {
+		Expect(SVG(io.Discard, g, Options{
+			Size:   300,
+			Markup: Markup{Triangles: []Point{{9, 0}}},
+		})).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,97 @@
+package ggo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Reason is how the game is won.
+type Reason byte
+
+const (
+	ByScore Reason = iota
+	ByResignation
+	ByTime
+	ByForfeit
+)
+
+// Result is outcome of the game. Draw is result by score with empty
+// winner.
+type Result struct {
+	Winner Color
+	Reason Reason
+	// Score is winning margin in points for result by score.
+	Score float64
+}
+
+// String writes result in SGF form, e.g. "B+3.5", "W+R", "B+T" or "0"
+// for draw.
+func (r Result) String() string {
+	if r.Winner == Empty {
+		return "0"
+	}
+	winner := "B+"
+	if r.Winner == White {
+		winner = "W+"
+	}
+	switch r.Reason {
+	case ByResignation:
+		return winner + "R"
+	case ByTime:
+		return winner + "T"
+	case ByForfeit:
+		return winner + "F"
+	}
+	return winner + strconv.FormatFloat(r.Score, 'f', -1, 64)
+}
+
+// ParseResult reads result in SGF form. Long forms as "B+Resign" and
+// "W+Time" are accepted too.
+func ParseResult(s string) (Result, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || strings.EqualFold(s, "draw") {
+		return Result{}, nil
+	}
+	parts := strings.SplitN(s, "+", 2)
+	if len(parts) != 2 {
+		return Result{}, fmt.Errorf("unknown result %q", s)
+	}
+	r := Result{}
+	switch strings.ToUpper(parts[0]) {
+	case "B":
+		r.Winner = Black
+	case "W":
+		r.Winner = White
+	default:
+		return Result{}, fmt.Errorf("unknown winner of result %q", s)
+	}
+	switch strings.ToUpper(parts[1]) {
+	case "R", "RESIGN":
+		r.Reason = ByResignation
+	case "T", "TIME":
+		r.Reason = ByTime
+	case "F", "FORFEIT":
+		r.Reason = ByForfeit
+	default:
+		score, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("unknown reason of result %q", s)
+		}
+		r.Score = score
+	}
+	return r, nil
+}
+
+func (r Result) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Result) UnmarshalText(text []byte) error {
+	result, err := ParseResult(string(text))
+	if err != nil {
+		return err
+	}
+	*r = result
+	return nil
+}
@@ -0,0 +1,133 @@
+package ggo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/someanon/ggo/timer"
+)
+
+// ingPenalty is points lost for each overtime period started in Ing
+// system.
+const ingPenalty = 2
+
+// Score is breakdown of result by area scoring.
+type Score struct {
+	// Area is count of stones and territory of color.
+	Area map[Color]int `json:"area"`
+	Komi float64       `json:"komi"`
+	// Penalty is points lost by color for overtime periods of Ing system.
+	Penalty map[Color]float64 `json:"penalty,omitempty"`
+	Result  Result            `json:"result"`
+}
+
+// Score counts area of the game ended by passes, i.e. stones and empty
+// regions bordered by stones of one color, after dead stones are
+// removed. Dead stones are places given by row and column. Komi is
+// added to white, penalty points are taken from color. Result of the
+// game is set to the score.
+func (g *Game) Score(dead [][2]int) (Score, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.phase != Over {
+		return Score{}, errors.New("game isn't over")
+	}
+	if g.result != nil && g.result.Reason != ByScore {
+		return Score{}, errors.New("game isn't decided by score")
+	}
+
+	size := g.board.size
+	colors := make([][]Color, size)
+	for r := range colors {
+		colors[r] = make([]Color, size)
+		for c := range colors[r] {
+			colors[r][c] = g.board.places[r][c].color
+		}
+	}
+	for _, d := range dead {
+		r, c := d[0], d[1]
+		if r < 0 || c < 0 || r >= size || c >= size || colors[r][c] == Empty {
+			return Score{}, fmt.Errorf("no stone at row=%d, column=%d", r, c)
+		}
+		colors[r][c] = Empty
+	}
+
+	s := Score{
+		Area:    map[Color]int{Black: 0, White: 0},
+		Komi:    g.parameters.Komi,
+		Penalty: map[Color]float64{},
+	}
+	visited := make(map[[2]int]nothing)
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			if colors[r][c] != Empty {
+				s.Area[colors[r][c]]++
+				continue
+			}
+			if _, ok := visited[[2]int{r, c}]; ok {
+				continue
+			}
+			count, owner := region(colors, r, c, visited)
+			if owner != Empty {
+				s.Area[owner] += count
+			}
+		}
+	}
+
+	for _, color := range []Color{Black, White} {
+		ts := g.parameters.TimeSystemOf(color)
+		if ts != nil && ts.System == timer.Ing {
+			if periods := ts.PeriodsStarted(g.clockState(color)); periods > 0 {
+				s.Penalty[color] = float64(ingPenalty * periods)
+			}
+		}
+	}
+
+	margin := float64(s.Area[Black]) - s.Penalty[Black] - (float64(s.Area[White]) + s.Komi - s.Penalty[White])
+	switch {
+	case margin > 0:
+		s.Result = Result{Winner: Black, Score: margin}
+	case margin < 0:
+		s.Result = Result{Winner: White, Score: math.Abs(margin)}
+	}
+	result := s.Result
+	g.result = &result
+	g.info.Result = result.String()
+	return s, nil
+}
+
+// region returns size of empty region at row and column and color of
+// its border, which is empty when the region borders both colors.
+func region(colors [][]Color, row int, column int, visited map[[2]int]nothing) (int, Color) {
+	size := len(colors)
+	count := 0
+	border := map[Color]nothing{}
+	stack := [][2]int{{row, column}}
+	visited[[2]int{row, column}] = nothing{}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		count++
+		for _, n := range [][2]int{{p[0] - 1, p[1]}, {p[0] + 1, p[1]}, {p[0], p[1] - 1}, {p[0], p[1] + 1}} {
+			if n[0] < 0 || n[1] < 0 || n[0] >= size || n[1] >= size {
+				continue
+			}
+			if color := colors[n[0]][n[1]]; color != Empty {
+				border[color] = nothing{}
+				continue
+			}
+			if _, ok := visited[n]; !ok {
+				visited[n] = nothing{}
+				stack = append(stack, n)
+			}
+		}
+	}
+	if len(border) != 1 {
+		return count, Empty
+	}
+	for color := range border {
+		return count, color
+	}
+	return count, Empty
+}
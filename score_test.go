@@ -0,0 +1,69 @@
+package ggo
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Score", func() {
+	var g *Game
+	BeforeEach(func() {
+		var err error
+		g, err = ParseGame(`
+			. X O . .
+			. X O . .
+			. X O . X
+			. X O . .
+			. X O . .
+		`, Black)
+		Expect(err).ToNot(HaveOccurred())
+		g.parameters.Komi = 0.5
+	})
+	It("counts area without dead stones", func() {
+		_, err := g.Score(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(g.Pass(Black)).To(Succeed())
+		Expect(g.Pass(White)).To(Succeed())
+		_, err = g.Score([][2]int{{0, 0}})
+		Expect(err).To(HaveOccurred())
+
+		s, err := g.Score([][2]int{{2, 4}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s).To(Equal(Score{
+			Area:    map[Color]int{Black: 10, White: 15},
+			Komi:    0.5,
+			Penalty: map[Color]float64{},
+			Result:  Result{Winner: White, Score: 5.5},
+		}))
+		Expect(g.Info().Result).To(Equal("W+5.5"))
+
+		s, err = g.Score(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Area).To(Equal(map[Color]int{Black: 11, White: 5}))
+		result, _ := g.Result()
+		Expect(result).To(Equal(Result{Winner: Black, Score: 5.5}))
+	})
+	It("takes penalty points of Ing overtime", func() {
+		ing := &timer.Parameters{System: timer.Ing, Base: time.Second, ByoYomi: 10 * time.Second, Periods: 3}
+		g.parameters.TimeSystem = ing
+		g.savedClocks = map[Color]timer.State{
+			Black: {ByoYomi: 10 * time.Second, Periods: 3},
+			White: {ByoYomi: 5 * time.Second, Periods: 2},
+		}
+		Expect(g.Pass(Black)).To(Succeed())
+		Expect(g.Pass(White)).To(Succeed())
+		s, err := g.Score([][2]int{{2, 4}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Penalty).To(Equal(map[Color]float64{Black: 2, White: 4}))
+		Expect(s.Result).To(Equal(Result{Winner: White, Score: 3.5}))
+	})
+	It("isn't counted for game over by time", func() {
+		g.phase = Over
+		g.result = &Result{Winner: Black, Reason: ByTime}
+		_, err := g.Score(nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,446 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/coord"
+	"github.com/someanon/ggo/timer"
+)
+
+// FromGame returns game tree with parameters, info, setup stones and
+// moves of the game. SGF has no time settings per color, so TM and OT
+// are time system of black and different time system of white is
+// written to private TMW and OTW properties. Lag allowance is written to
+// private LG (LGW of white) property as "<per move>:<per game>" seconds,
+// move time limit to private ML (MLW) property in seconds.
+// Clock records of moves are written as time left and overtime left,
+// e.g. BL and OB, and time taken by the move to private ET property in
+// seconds.
+func FromGame(g *ggo.Game) (*Node, error) {
+	parameters := g.Parameters()
+	size := g.Size()
+	if size > coord.MaxSGFSize {
+		return nil, errors.New("sgf: too large board")
+	}
+
+	root := &Node{}
+	root.Set("GM", "1")
+	root.Set("FF", "4")
+	root.Set("CA", "UTF-8")
+	root.Set("AP", "ggo")
+	root.Set("SZ", strconv.Itoa(size))
+	root.Set("KM", strconv.FormatFloat(parameters.Komi, 'f', -1, 64))
+	for _, p := range []struct {
+		tm, ot, lg, ml string
+		ts             *timer.Parameters
+	}{
+		{"TM", "OT", "LG", "ML", parameters.TimeSystem}, {"TMW", "OTW", "LGW", "MLW", parameters.WhiteTimeSystem},
+	} {
+		if p.ts == nil {
+			continue
+		}
+		tm, ot := formatTime(*p.ts)
+		root.Set(p.tm, tm)
+		if ot != "" {
+			root.Set(p.ot, ot)
+		}
+		if p.ts.Lag != nil {
+			root.Set(p.lg, formatLag(*p.ts.Lag))
+		}
+		if p.ts.MoveLimit > 0 {
+			root.Set(p.ml, formatSeconds(p.ts.MoveLimit))
+		}
+	}
+
+	info := g.Info()
+	for _, p := range []struct {
+		id    string
+		value string
+	}{
+		{"PB", info.BlackName}, {"BR", info.BlackRank},
+		{"PW", info.WhiteName}, {"WR", info.WhiteRank},
+		{"DT", info.Date}, {"EV", info.Event}, {"PC", info.Place},
+		{"RE", info.Result},
+	} {
+		if p.value != "" {
+			root.Set(p.id, p.value)
+		}
+	}
+
+	for _, s := range g.SetupStones() {
+		place, _ := coord.Format(s.Row, s.Column, size, coord.SGF)
+		root.Add(colorID(s.Color, "A"), place)
+	}
+
+	moves := g.Moves()
+	if len(moves) > 0 && moves[0].Color == ggo.White || len(moves) == 0 && g.MoveColor() == ggo.White {
+		root.Set("PL", "W")
+	}
+
+	last := root
+	for _, m := range moves {
+		place := ""
+		if !m.Pass {
+			place, _ = coord.Format(m.Row, m.Column, size, coord.SGF)
+		}
+		n := &Node{}
+		n.Set(colorID(m.Color, ""), place)
+		if m.Time != nil {
+			left, overtime := timeIDs(m.Color)
+			n.Set(left, formatSeconds(m.Time.Left.Round(time.Millisecond)))
+			if m.Time.Overtime > 0 {
+				n.Set(overtime, strconv.Itoa(m.Time.Overtime))
+			}
+			if m.Time.Elapsed > 0 {
+				n.Set("ET", formatSeconds(m.Time.Elapsed.Round(time.Millisecond)))
+			}
+		}
+		last.Children = append(last.Children, n)
+		last = n
+	}
+	return root, nil
+}
+
+// Convert reads game record of other format by read and writes it as
+// SGF.
+func Convert(read func(r io.Reader) (*ggo.Game, error), r io.Reader, w io.Writer) error {
+	g, err := read(r)
+	if err != nil {
+		return err
+	}
+	root, err := FromGame(g)
+	if err != nil {
+		return err
+	}
+	return Write(w, root)
+}
+
+// ToGame replays main line of the game tree. Variations are ignored,
+// setup is allowed only in the root node.
+func ToGame(root *Node) (*ggo.Game, error) {
+	parameters := ggo.Parameters{BoardSize: 19}
+	if v, ok := root.Value("SZ"); ok {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid board size %q", v)
+		}
+		parameters.BoardSize = size
+	}
+	if parameters.BoardSize < 1 || parameters.BoardSize > coord.MaxSGFSize {
+		return nil, fmt.Errorf("sgf: unsupported board size %d", parameters.BoardSize)
+	}
+	if v, ok := root.Value("KM"); ok {
+		komi, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid komi %q", v)
+		}
+		parameters.Komi = komi
+	}
+	for _, p := range []struct {
+		tm, ot, lg, ml string
+		ts             **timer.Parameters
+	}{
+		{"TM", "OT", "LG", "ML", &parameters.TimeSystem}, {"TMW", "OTW", "LGW", "MLW", &parameters.WhiteTimeSystem},
+	} {
+		if tm, ok := root.Value(p.tm); ok {
+			ot, _ := root.Value(p.ot)
+			ts, err := parseTime(tm, ot)
+			if err != nil {
+				return nil, err
+			}
+			if lg, ok := root.Value(p.lg); ok && ts != nil {
+				if ts.Lag, err = parseLag(lg); err != nil {
+					return nil, err
+				}
+			}
+			if ml, ok := root.Value(p.ml); ok && ts != nil {
+				limit, err := strconv.ParseFloat(strings.TrimSpace(ml), 64)
+				if err != nil {
+					return nil, fmt.Errorf("sgf: invalid move limit %q", ml)
+				}
+				ts.MoveLimit = seconds(limit)
+			}
+			*p.ts = ts
+		}
+	}
+	if err := parameters.Validate(); err != nil {
+		return nil, fmt.Errorf("sgf: %v", err)
+	}
+
+	g := ggo.NewGame(parameters)
+	size := parameters.BoardSize
+
+	info := ggo.Info{}
+	for _, p := range []struct {
+		id    string
+		value *string
+	}{
+		{"PB", &info.BlackName}, {"BR", &info.BlackRank},
+		{"PW", &info.WhiteName}, {"WR", &info.WhiteRank},
+		{"DT", &info.Date}, {"EV", &info.Event}, {"PC", &info.Place},
+		{"RE", &info.Result},
+	} {
+		*p.value, _ = root.Value(p.id)
+	}
+	g.SetInfo(info)
+
+	for _, color := range []ggo.Color{ggo.Black, ggo.White} {
+		places, err := expandPlaces(root.Values(colorID(color, "A")))
+		if err != nil {
+			return nil, err
+		}
+		for _, place := range places {
+			row, column, err := coord.Parse(place, size, coord.SGF)
+			if err != nil {
+				return nil, fmt.Errorf("sgf: %v", err)
+			}
+			if err := g.Setup(row, column, color); err != nil {
+				return nil, fmt.Errorf("sgf: invalid setup stone %q: %v", place, err)
+			}
+		}
+	}
+	if v, ok := root.Value("PL"); ok {
+		if err := g.SetMoveColor(parseColor(v)); err != nil {
+			return nil, fmt.Errorf("sgf: %v", err)
+		}
+	}
+
+	n := root
+	for i := 0; ; i++ {
+		if i > 0 {
+			for _, id := range []string{"AB", "AW", "AE"} {
+				if len(n.Values(id)) > 0 {
+					return nil, errors.New("sgf: setup is supported only in the root node")
+				}
+			}
+		}
+		for _, color := range []ggo.Color{ggo.Black, ggo.White} {
+			place, ok := n.Value(colorID(color, ""))
+			if !ok {
+				continue
+			}
+			if len(g.Moves()) == 0 && g.MoveColor() != color {
+				// Records without PL may start with white.
+				if err := g.SetMoveColor(color); err != nil {
+					return nil, fmt.Errorf("sgf: %v", err)
+				}
+			}
+			if err := play(g, place, color); err != nil {
+				return nil, fmt.Errorf("sgf: move %d: %v", len(g.Moves())+1, err)
+			}
+			if err := readMoveTime(g, n, color); err != nil {
+				return nil, err
+			}
+		}
+		if len(n.Children) == 0 {
+			break
+		}
+		n = n.Children[0]
+	}
+	return g, nil
+}
+
+// readMoveTime sets time of the last move from time left and overtime
+// left of color, and time taken by the move, unknown in records of other
+// programs.
+func readMoveTime(g *ggo.Game, n *Node, color ggo.Color) error {
+	leftID, overtimeID := timeIDs(color)
+	v, ok := n.Value(leftID)
+	if !ok {
+		return nil
+	}
+	left, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || left < 0 {
+		return fmt.Errorf("sgf: invalid time left %q", v)
+	}
+	mt := &ggo.MoveTime{Left: seconds(left)}
+	if v, ok := n.Value(overtimeID); ok {
+		if mt.Overtime, err = strconv.Atoi(strings.TrimSpace(v)); err != nil || mt.Overtime < 0 {
+			return fmt.Errorf("sgf: invalid overtime left %q", v)
+		}
+	}
+	if v, ok := n.Value("ET"); ok {
+		elapsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || elapsed < 0 {
+			return fmt.Errorf("sgf: invalid time taken %q", v)
+		}
+		mt.Elapsed = seconds(elapsed)
+	}
+	return g.SetMoveTime(len(g.Moves()), mt)
+}
+
+func play(g *ggo.Game, place string, color ggo.Color) error {
+	if coord.IsPass(place, g.Size(), coord.SGF) {
+		return g.Pass(color)
+	}
+	row, column, err := coord.Parse(place, g.Size(), coord.SGF)
+	if err != nil {
+		return err
+	}
+	return g.Move(row, column, color)
+}
+
+// expandPlaces expands compressed point lists, e.g. "aa:bb" to "aa",
+// "ba", "ab", "bb".
+func expandPlaces(values []string) ([]string, error) {
+	places := make([]string, 0, len(values))
+	for _, v := range values {
+		parts := strings.Split(v, ":")
+		if len(parts) == 1 {
+			places = append(places, v)
+			continue
+		}
+		if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+			return nil, fmt.Errorf("sgf: invalid point list %q", v)
+		}
+		from, to := parts[0], parts[1]
+		for row := from[1]; row <= to[1]; row++ {
+			for column := from[0]; column <= to[0]; column++ {
+				places = append(places, string([]byte{column, row}))
+			}
+		}
+	}
+	return places, nil
+}
+
+func colorID(color ggo.Color, prefix string) string {
+	if color == ggo.White {
+		return prefix + "W"
+	}
+	return prefix + "B"
+}
+
+// timeIDs returns identifiers of time left and overtime left of color.
+func timeIDs(color ggo.Color) (string, string) {
+	if color == ggo.White {
+		return "WL", "OW"
+	}
+	return "BL", "OB"
+}
+
+func parseColor(v string) ggo.Color {
+	switch strings.ToUpper(strings.TrimSpace(v)) {
+	case "B":
+		return ggo.Black
+	case "W":
+		return ggo.White
+	}
+	return ggo.Empty
+}
+
+// formatTime returns TM and OT values. Overtime is written in the most
+// common forms: "5x30 byo-yomi" and "25/600 Canadian", Canadian with
+// more periods is "3x25/600 Canadian", Ing is "3x2100 ing", other
+// systems are written as seconds and system name, e.g. "10 fischer".
+// Fractions of seconds are written as decimals, e.g. "5x2.5 byo-yomi".
+func formatTime(p timer.Parameters) (string, string) {
+	tm := formatSeconds(p.Base)
+	switch {
+	case p.System == timer.Fischer:
+		return tm, fmt.Sprintf("%s %v", formatSeconds(p.Increment), p.System)
+	case p.System == timer.SimpleDelay || p.System == timer.Bronstein:
+		return tm, fmt.Sprintf("%s %v", formatSeconds(p.Delay), p.System)
+	case p.System == timer.Hourglass || p.System == timer.Absolute:
+		return tm, p.System.String()
+	case p.System == timer.Ing:
+		return tm, fmt.Sprintf("%dx%s %v", p.Periods, formatSeconds(p.ByoYomi), p.System)
+	case p.ByoYomi == 0:
+		return tm, ""
+	case p.System == timer.Canadian && p.Periods > 1:
+		return tm, fmt.Sprintf("%dx%d/%s Canadian", p.Periods, p.Moves, formatSeconds(p.ByoYomi))
+	case p.System == timer.Canadian || p.Moves > 1:
+		return tm, fmt.Sprintf("%d/%s Canadian", p.Moves, formatSeconds(p.ByoYomi))
+	}
+	return tm, fmt.Sprintf("%dx%s byo-yomi", p.Periods, formatSeconds(p.ByoYomi))
+}
+
+func formatLag(l timer.Lag) string {
+	return formatSeconds(l.PerMove) + ":" + formatSeconds(l.PerGame)
+}
+
+func parseLag(v string) (*timer.Lag, error) {
+	parts := strings.Split(v, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("sgf: invalid lag %q", v)
+	}
+	var values [2]time.Duration
+	for i, part := range parts {
+		s, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid lag %q", v)
+		}
+		values[i] = seconds(s)
+	}
+	return &timer.Lag{PerMove: values[0], PerGame: values[1]}, nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
+
+func parseTime(tm string, ot string) (*timer.Parameters, error) {
+	base, err := strconv.ParseFloat(strings.TrimSpace(tm), 64)
+	if err != nil {
+		return nil, fmt.Errorf("sgf: invalid time %q", tm)
+	}
+	p := &timer.Parameters{Base: seconds(base)}
+	ot = strings.TrimSpace(ot)
+	if ot == "" {
+		if p.Base == 0 {
+			// No time limit.
+			return nil, nil
+		}
+		return p, nil
+	}
+	fields := strings.Fields(ot)
+	var (
+		a, c   int
+		b      float64
+		system timer.System
+	)
+	switch {
+	case strings.EqualFold(ot, timer.Hourglass.String()):
+		p.System = timer.Hourglass
+	case strings.EqualFold(ot, timer.Absolute.String()):
+		p.System = timer.Absolute
+	case strings.Contains(fields[0], "x") && strings.Contains(fields[0], "/"):
+		_, err = fmt.Sscanf(fields[0], "%dx%d/%g", &c, &a, &b)
+		p.System, p.Periods, p.Moves, p.ByoYomi = timer.Canadian, c, a, seconds(b)
+	case strings.Contains(fields[0], "x"):
+		_, err = fmt.Sscanf(fields[0], "%dx%g", &a, &b)
+		p.Periods, p.ByoYomi, p.Moves = a, seconds(b), 1
+		if len(fields) > 1 && strings.EqualFold(fields[1], timer.Ing.String()) {
+			p.System, p.Moves = timer.Ing, 0
+		}
+	case strings.Contains(fields[0], "/"):
+		_, err = fmt.Sscanf(fields[0], "%d/%g", &a, &b)
+		p.System, p.Moves, p.ByoYomi, p.Periods = timer.Canadian, a, seconds(b), 1
+	case len(fields) > 1 && system.UnmarshalText([]byte(strings.ToLower(strings.Join(fields[1:], " ")))) == nil &&
+		(system == timer.Fischer || system == timer.SimpleDelay || system == timer.Bronstein):
+		_, err = fmt.Sscanf(fields[0], "%g", &b)
+		p.System = system
+		if system == timer.Fischer {
+			p.Increment = seconds(b)
+		} else {
+			p.Delay = seconds(b)
+		}
+	default:
+		// Unknown overtime is kept as absolute time.
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sgf: invalid overtime %q", ot)
+	}
+	return p, nil
+}
@@ -0,0 +1,284 @@
+package sgf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+type Property struct {
+	ID     string
+	Values []string
+}
+
+// Node is SGF node. Main line continues with the first child, other
+// children are variations.
+type Node struct {
+	Properties []Property
+	Children   []*Node
+}
+
+// Value returns the first value of property.
+func (n *Node) Value(id string) (string, bool) {
+	values := n.Values(id)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+func (n *Node) Values(id string) []string {
+	for _, p := range n.Properties {
+		if p.ID == id {
+			return p.Values
+		}
+	}
+	return nil
+}
+
+// Set replaces values of property or adds property to the end.
+func (n *Node) Set(id string, values ...string) {
+	for i, p := range n.Properties {
+		if p.ID == id {
+			n.Properties[i].Values = values
+			return
+		}
+	}
+	n.Properties = append(n.Properties, Property{ID: id, Values: values})
+}
+
+// Add appends values to property.
+func (n *Node) Add(id string, values ...string) {
+	for i, p := range n.Properties {
+		if p.ID == id {
+			n.Properties[i].Values = append(n.Properties[i].Values, values...)
+			return
+		}
+	}
+	n.Properties = append(n.Properties, Property{ID: id, Values: values})
+}
+
+// Parse reads SGF collection and returns root nodes of its game trees.
+func Parse(r io.Reader) ([]*Node, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{data: data}
+	var trees []*Node
+	for {
+		p.skipSpace()
+		if p.end() {
+			break
+		}
+		tree, err := p.tree()
+		if err != nil {
+			return nil, fmt.Errorf("sgf: %v at offset %d", err, p.pos)
+		}
+		trees = append(trees, tree)
+	}
+	if len(trees) == 0 {
+		return nil, errors.New("sgf: no game trees")
+	}
+	return trees, nil
+}
+
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func (p *parser) end() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *parser) skipSpace() {
+	for !p.end() && strings.IndexByte(" \t\r\n", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) expect(b byte) error {
+	p.skipSpace()
+	if p.end() {
+		return fmt.Errorf("expected %q but got end of data", b)
+	}
+	if p.data[p.pos] != b {
+		return fmt.Errorf("expected %q but got %q", b, p.data[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// tree reads "(" sequence of nodes and subtrees ")".
+func (p *parser) tree() (*Node, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var first, last *Node
+	for {
+		p.skipSpace()
+		if p.end() {
+			return nil, errors.New("unexpected end of data")
+		}
+		switch p.data[p.pos] {
+		case ';':
+			p.pos++
+			n, err := p.node()
+			if err != nil {
+				return nil, err
+			}
+			if first == nil {
+				first = n
+			} else {
+				last.Children = append(last.Children, n)
+			}
+			last = n
+		case '(':
+			if last == nil {
+				return nil, errors.New("game tree without nodes")
+			}
+			child, err := p.tree()
+			if err != nil {
+				return nil, err
+			}
+			last.Children = append(last.Children, child)
+		case ')':
+			if first == nil {
+				return nil, errors.New("game tree without nodes")
+			}
+			p.pos++
+			return first, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q", p.data[p.pos])
+		}
+	}
+}
+
+func (p *parser) node() (*Node, error) {
+	n := &Node{}
+	for {
+		p.skipSpace()
+		if p.end() || !isUpper(p.data[p.pos]) && !isLower(p.data[p.pos]) {
+			return n, nil
+		}
+		start := p.pos
+		var id []byte
+		for !p.end() && (isUpper(p.data[p.pos]) || isLower(p.data[p.pos])) {
+			// Old SGF allowed lower case letters in identifiers, they are
+			// ignored, e.g. "AddBlack" is "AB".
+			if isUpper(p.data[p.pos]) {
+				id = append(id, p.data[p.pos])
+			}
+			p.pos++
+		}
+		if len(id) == 0 {
+			p.pos = start
+			return nil, errors.New("property identifier without upper case letters")
+		}
+		var values []string
+		for {
+			p.skipSpace()
+			if p.end() || p.data[p.pos] != '[' {
+				break
+			}
+			p.pos++
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("property %s without values", id)
+		}
+		n.Add(string(id), values...)
+	}
+}
+
+// value reads property value up to unescaped "]". Escaped line breaks
+// are removed, other escaped characters are kept as is.
+func (p *parser) value() (string, error) {
+	var buf bytes.Buffer
+	for !p.end() {
+		b := p.data[p.pos]
+		p.pos++
+		switch b {
+		case ']':
+			return buf.String(), nil
+		case '\\':
+			if p.end() {
+				return "", errors.New("unexpected end of data")
+			}
+			b = p.data[p.pos]
+			p.pos++
+			if b == '\n' || b == '\r' {
+				if !p.end() && (p.data[p.pos] == '\n' || p.data[p.pos] == '\r') &&
+					p.data[p.pos] != b {
+					p.pos++
+				}
+				continue
+			}
+			buf.WriteByte(b)
+		default:
+			buf.WriteByte(b)
+		}
+	}
+	return "", errors.New("unterminated property value")
+}
+
+// Write writes game trees as SGF collection. Each node of the main line
+// starts a new line.
+func Write(w io.Writer, trees ...*Node) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range trees {
+		writeTree(bw, t)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+func writeTree(w *bufio.Writer, n *Node) {
+	w.WriteByte('(')
+	for {
+		writeNode(w, n)
+		if len(n.Children) != 1 {
+			break
+		}
+		w.WriteByte('\n')
+		n = n.Children[0]
+	}
+	for _, child := range n.Children {
+		w.WriteByte('\n')
+		writeTree(w, child)
+	}
+	w.WriteByte(')')
+}
+
+func writeNode(w *bufio.Writer, n *Node) {
+	w.WriteByte(';')
+	for _, p := range n.Properties {
+		w.WriteString(p.ID)
+		for _, v := range p.Values {
+			w.WriteByte('[')
+			w.WriteString(escape(v))
+			w.WriteByte(']')
+		}
+	}
+}
+
+func escape(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(v)
+}
+
+func isUpper(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+func isLower(b byte) bool {
+	return b >= 'a' && b <= 'z'
+}
@@ -0,0 +1,13 @@
+package sgf_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSgf(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sgf Suite")
+}
@@ -0,0 +1,218 @@
+package sgf_test
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	. "github.com/someanon/ggo/sgf"
+	"github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("SGF", func() {
+	Describe("parsing", func() {
+		It("reads nodes, properties and variations", func() {
+			trees, err := Parse(strings.NewReader(`
+				(;GM[1]SZ[9]C[a \] b \\ c\
+d]AB[aa][bb]
+				;B[cc];W[dd]
+				(;B[ee])
+				(;B[ff]C[second]))
+				(;GM[1]SZ[19])
+			`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trees).To(HaveLen(2))
+			root := trees[0]
+			Expect(root.Values("C")).To(Equal([]string{`a ] b \ cd`}))
+			Expect(root.Values("AB")).To(Equal([]string{"aa", "bb"}))
+			Expect(root.Children).To(HaveLen(1))
+			w := root.Children[0].Children[0]
+			Expect(w.Values("W")).To(Equal([]string{"dd"}))
+			Expect(w.Children).To(HaveLen(2))
+			Expect(w.Children[1].Values("C")).To(Equal([]string{"second"}))
+		})
+		It("ignores lower case letters of old identifiers", func() {
+			trees, err := Parse(strings.NewReader(`(;AddBlack[aa])`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trees[0].Values("AB")).To(Equal([]string{"aa"}))
+		})
+		It("rejects broken data", func() {
+			for _, s := range []string{"", "(", "(;B[aa]", "(;B)", "()", "(;B[aa]))"} {
+				_, err := Parse(strings.NewReader(s))
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+	})
+	Describe("writing", func() {
+		It("writes what was read", func() {
+			data := "(;GM[1]C[a \\] b \\\\ c]\n;B[cc]\n;W[dd]\n(;B[ee])\n(;B[ff]))\n"
+			trees, err := Parse(strings.NewReader(data))
+			Expect(err).ToNot(HaveOccurred())
+			var buf bytes.Buffer
+			Expect(Write(&buf, trees...)).To(Succeed())
+			Expect(buf.String()).To(Equal(data))
+		})
+	})
+	Describe("game", func() {
+		It("keeps game through SGF", func() {
+			g := ggo.NewGame(ggo.Parameters{
+				BoardSize:  9,
+				Komi:       0.5,
+				TimeSystem: &timer.Parameters{Base: 600 * time.Second, ByoYomi: 30 * time.Second, Periods: 5, Moves: 1},
+			})
+			Expect(g.SetupHandicap(2)).To(Succeed())
+			g.SetInfo(ggo.Info{BlackName: "b", BlackRank: "3k", WhiteName: "w]", Result: "W+R"})
+			Expect(g.Play("E5", ggo.White)).To(Succeed())
+			Expect(g.Play("pass", ggo.Black)).To(Succeed())
+			Expect(g.Play("F5", ggo.White)).To(Succeed())
+
+			root, err := FromGame(g)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(root.Values("OT")).To(Equal([]string{"5x30 byo-yomi"}))
+			var buf bytes.Buffer
+			Expect(Write(&buf, root)).To(Succeed())
+
+			trees, err := Parse(&buf)
+			Expect(err).ToNot(HaveOccurred())
+			restored, err := ToGame(trees[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored.Parameters()).To(Equal(g.Parameters()))
+			Expect(restored.Info()).To(Equal(g.Info()))
+			Expect(restored.SetupStones()).To(Equal(g.SetupStones()))
+			Expect(restored.Moves()).To(Equal(g.Moves()))
+		})
+		It("keeps other time systems through SGF", func() {
+			for ot, ts := range map[string]*timer.Parameters{
+				"10 fischer":        {System: timer.Fischer, Base: 300 * time.Second, Increment: 10 * time.Second},
+				"5 simple delay":    {System: timer.SimpleDelay, Base: 300 * time.Second, Delay: 5 * time.Second},
+				"5 bronstein":       {System: timer.Bronstein, Base: 300 * time.Second, Delay: 5 * time.Second},
+				"25/600 Canadian":   {System: timer.Canadian, Base: 300 * time.Second, ByoYomi: 600 * time.Second, Moves: 25, Periods: 1},
+				"3x10/300 Canadian": {System: timer.Canadian, Base: 300 * time.Second, ByoYomi: 300 * time.Second, Moves: 10, Periods: 3},
+				"hourglass":         {System: timer.Hourglass, Base: 300 * time.Second},
+				"3x2100 ing":        {System: timer.Ing, Base: 300 * time.Second, ByoYomi: 2100 * time.Second, Periods: 3},
+				"absolute":          {System: timer.Absolute, Base: 300 * time.Second},
+			} {
+				root, err := FromGame(ggo.NewGame(ggo.Parameters{BoardSize: 9, TimeSystem: ts}))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(root.Values("OT")).To(Equal([]string{ot}))
+				g, err := ToGame(root)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(g.Parameters().TimeSystem).To(Equal(ts))
+			}
+		})
+		It("keeps main time of unknown overtime", func() {
+			for _, ot := range []string{"30 canadian", "3 ing", "5 foo"} {
+				trees, err := Parse(strings.NewReader("(;SZ[9]TM[300]OT[" + ot + "])"))
+				Expect(err).ToNot(HaveOccurred())
+				g, err := ToGame(trees[0])
+				Expect(err).ToNot(HaveOccurred(), ot)
+				Expect(g.Parameters().TimeSystem).To(Equal(&timer.Parameters{Base: 300 * time.Second}), ot)
+			}
+		})
+		It("keeps fractions of seconds through SGF", func() {
+			ts := &timer.Parameters{Base: 90500 * time.Millisecond, ByoYomi: 2500 * time.Millisecond, Periods: 3, Moves: 1}
+			root, err := FromGame(ggo.NewGame(ggo.Parameters{BoardSize: 9, TimeSystem: ts}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(root.Values("TM")).To(Equal([]string{"90.5"}))
+			Expect(root.Values("OT")).To(Equal([]string{"3x2.5 byo-yomi"}))
+			g, err := ToGame(root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Parameters().TimeSystem).To(Equal(ts))
+		})
+		It("keeps time left and time taken of moves through SGF", func() {
+			trees, err := Parse(strings.NewReader(
+				`(;SZ[5]TM[60]OT[3x10 byo-yomi];B[aa]BL[50.5];W[bb]WL[7]OW[2]ET[3.25];B[cc])`))
+			Expect(err).ToNot(HaveOccurred())
+			g, err := ToGame(trees[0])
+			Expect(err).ToNot(HaveOccurred())
+			moves := g.Moves()
+			Expect(moves[0].Time).To(Equal(&ggo.MoveTime{Left: 50500 * time.Millisecond}))
+			Expect(moves[1].Time).To(Equal(&ggo.MoveTime{Elapsed: 3250 * time.Millisecond, Left: 7 * time.Second, Overtime: 2}))
+			Expect(moves[2].Time).To(BeNil())
+			root, err := FromGame(g)
+			Expect(err).ToNot(HaveOccurred())
+			var buf bytes.Buffer
+			Expect(Write(&buf, root)).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring(";B[aa]BL[50.5]\n;W[bb]WL[7]OW[2]ET[3.25]\n;B[cc])"))
+
+			for _, s := range []string{`(;SZ[5];B[aa]BL[soon])`, `(;SZ[5];B[aa]BL[5]ET[-1])`} {
+				trees, err = Parse(strings.NewReader(s))
+				Expect(err).ToNot(HaveOccurred())
+				_, err = ToGame(trees[0])
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+		It("keeps time odds through SGF", func() {
+			parameters := ggo.Parameters{
+				BoardSize:       9,
+				TimeSystem:      &timer.Parameters{Base: 600 * time.Second, ByoYomi: 30 * time.Second, Periods: 5, Moves: 1},
+				WhiteTimeSystem: &timer.Parameters{Base: 300 * time.Second},
+			}
+			root, err := FromGame(ggo.NewGame(parameters))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(root.Values("TMW")).To(Equal([]string{"300"}))
+			Expect(root.Values("OTW")).To(BeEmpty())
+			g, err := ToGame(root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Parameters()).To(Equal(parameters))
+		})
+		It("keeps lag allowance through SGF", func() {
+			parameters := ggo.Parameters{
+				BoardSize: 9,
+				TimeSystem: &timer.Parameters{Base: 600 * time.Second,
+					Lag: &timer.Lag{PerMove: 300 * time.Millisecond, PerGame: 5 * time.Second}},
+				WhiteTimeSystem: &timer.Parameters{Base: 300 * time.Second, Lag: &timer.Lag{PerMove: time.Second}},
+			}
+			root, err := FromGame(ggo.NewGame(parameters))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(root.Values("LG")).To(Equal([]string{"0.3:5"}))
+			Expect(root.Values("LGW")).To(Equal([]string{"1:0"}))
+			g, err := ToGame(root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Parameters()).To(Equal(parameters))
+
+			trees, err := Parse(strings.NewReader(`(;SZ[5]TM[60]LG[fast])`))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ToGame(trees[0])
+			Expect(err).To(HaveOccurred())
+		})
+		It("keeps move time limit through SGF", func() {
+			parameters := ggo.Parameters{
+				BoardSize:       9,
+				TimeSystem:      &timer.Parameters{System: timer.Absolute, Base: 600 * time.Second, MoveLimit: 30 * time.Second},
+				WhiteTimeSystem: &timer.Parameters{System: timer.Absolute, Base: 300 * time.Second, MoveLimit: 1500 * time.Millisecond},
+			}
+			root, err := FromGame(ggo.NewGame(parameters))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(root.Values("ML")).To(Equal([]string{"30"}))
+			Expect(root.Values("MLW")).To(Equal([]string{"1.5"}))
+			g, err := ToGame(root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Parameters()).To(Equal(parameters))
+
+			trees, err := Parse(strings.NewReader(`(;SZ[5]TM[60]ML[soon])`))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ToGame(trees[0])
+			Expect(err).To(HaveOccurred())
+		})
+		It("reads compressed setup and white to play", func() {
+			trees, err := Parse(strings.NewReader(`(;SZ[5]AB[aa:bb]AW[ee]PL[W];W[cc];B[tt])`))
+			Expect(err).ToNot(HaveOccurred())
+			g, err := ToGame(trees[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.SetupStones()).To(HaveLen(5))
+			Expect(g.Moves()).To(HaveLen(2))
+			Expect(g.Moves()[0].Color).To(Equal(ggo.White))
+			Expect(g.Moves()[1].Pass).To(BeTrue())
+		})
+		It("rejects illegal moves", func() {
+			trees, err := Parse(strings.NewReader(`(;SZ[5];B[aa];W[aa])`))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ToGame(trees[0])
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
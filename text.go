@@ -0,0 +1,167 @@
+package ggo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/someanon/ggo/coord"
+)
+
+const koSymbol = '#'
+
+// String renders position as text diagram. Black stones are X, white
+// stones are O, place forbidden by ko is #, last move is put in
+// parentheses. Columns are lettered without I and rows are numbered
+// from the bottom.
+func (g *Game) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	size := g.board.size
+	labelWidth := len(fmt.Sprint(size))
+
+	lastRow, lastColumn := -1, -1
+	if n := len(g.history); n > 0 && !g.history[n-1].Pass {
+		lastRow, lastColumn = g.history[n-1].Row, g.history[n-1].Column
+	}
+
+	var buf bytes.Buffer
+
+	header := func() {
+		buf.WriteString(strings.Repeat(" ", labelWidth))
+		for c := 0; c < size; c++ {
+			letter := coord.GTPColumn(c)
+			if letter == "" {
+				letter = "?"
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(letter)
+		}
+		buf.WriteByte('\n')
+	}
+
+	header()
+	for r := 0; r < size; r++ {
+		label := fmt.Sprintf("%*d", labelWidth, size-r)
+		buf.WriteString(label)
+		for c := 0; c < size; c++ {
+			switch {
+			case r == lastRow && c == lastColumn:
+				buf.WriteByte('(')
+			case r == lastRow && c == lastColumn+1:
+				buf.WriteByte(')')
+			default:
+				buf.WriteByte(' ')
+			}
+			p := g.board.places[r][c]
+			if p == g.board.koPlace {
+				buf.WriteByte(koSymbol)
+			} else {
+				buf.WriteByte(p.color.symbol())
+			}
+		}
+		if r == lastRow && lastColumn == size-1 {
+			buf.WriteByte(')')
+		} else {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(label)
+		buf.WriteByte('\n')
+	}
+	header()
+
+	return buf.String()
+}
+
+// ParseGame builds game from text diagram as rendered by Game.String.
+// Coordinates and last move parentheses are optional and ignored, ko
+// place may be marked with #. Stones are put as setup, moveColor is
+// color to move next.
+func ParseGame(diagram string, moveColor Color) (*Game, error) {
+	if moveColor != Black && moveColor != White {
+		return nil, errors.New("move color should be black or white")
+	}
+
+	rows, ko, err := parseDiagram(diagram)
+	if err != nil {
+		return nil, err
+	}
+
+	g := NewGame(Parameters{BoardSize: len(rows)})
+	for r, row := range rows {
+		for c, color := range row {
+			if color == Empty {
+				continue
+			}
+			if err := g.Setup(r, c, color); err != nil {
+				return nil, fmt.Errorf("invalid stone at row=%d, column=%d: %v",
+					r, c, err)
+			}
+		}
+	}
+	g.moveColor = moveColor
+	if ko != nil {
+		g.board.koPlace = g.board.places[ko[0]][ko[1]]
+	}
+	g.computeDisallowedMoves()
+	return g, nil
+}
+
+func parseDiagram(diagram string) ([][]Color, *[2]int, error) {
+	var (
+		rows [][]Color
+		ko   *[2]int
+	)
+	for _, line := range strings.Split(diagram, "\n") {
+		if isDiagramHeader(line) {
+			continue
+		}
+		row := make([]Color, 0, len(line))
+		for _, ch := range line {
+			switch ch {
+			case '.', '+', ',':
+				row = append(row, Empty)
+			case koSymbol:
+				if ko != nil {
+					return nil, nil, errors.New("more than one ko place")
+				}
+				ko = &[2]int{len(rows), len(row)}
+				row = append(row, Empty)
+			case 'X', 'x':
+				row = append(row, Black)
+			case 'O', 'o':
+				row = append(row, White)
+			case ' ', '\t', '\r', '(', ')',
+				'0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			default:
+				return nil, nil, fmt.Errorf("unexpected symbol %q", ch)
+			}
+		}
+		if len(row) == 0 {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, nil, errors.New("empty diagram")
+	}
+	for _, row := range rows {
+		if len(row) != len(rows) {
+			return nil, nil, errors.New("diagram should be square")
+		}
+	}
+	return rows, ko, nil
+}
+
+// isDiagramHeader reports whether line is a column letters line. Such
+// line contains letters other than X and O, which are also stones.
+func isDiagramHeader(line string) bool {
+	for _, ch := range line {
+		if ch >= 'A' && ch <= 'Z' && ch != 'X' && ch != 'O' ||
+			ch >= 'a' && ch <= 'z' && ch != 'x' && ch != 'o' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package ggo
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Text", func() {
+	Describe("rendering", func() {
+		It("draws coordinates, ko and last move", func() {
+			g, err := ParseGame(`
+				. X O . .
+				X O . O .
+				. X O . .
+				. . . . .
+				. . . . .
+			`, Black)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Move(1, 2, Black)).To(Succeed())
+			Expect(g.String()).To(Equal("" +
+				"  A B C D E\n" +
+				"5 . X O . . 5\n" +
+				"4 X #(X)O . 4\n" +
+				"3 . X O . . 3\n" +
+				"2 . . . . . 2\n" +
+				"1 . . . . . 1\n" +
+				"  A B C D E\n"))
+		})
+	})
+	Describe("parsing", func() {
+		It("reads rendered diagram back", func() {
+			g, err := ParseGame(`
+				   A B C
+				 3 . X O 3
+				 2 X(O). 2
+				 1 . . . 1
+				   A B C
+			`, White)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.board.rows()).To(Equal([]string{".XO", "XO.", "..."}))
+			Expect(g.MoveColor()).To(Equal(White))
+		})
+		It("reads ko place", func() {
+			g, err := ParseGame(`
+				. X O .
+				X # X O
+				. X O .
+				. . . .
+			`, White)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Move(1, 1, White)).ToNot(Succeed())
+		})
+		It("rejects not square diagram", func() {
+			_, err := ParseGame(`
+				. X O
+				X O .
+			`, Black)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("rules", func() {
+		play := func(diagram string, color Color, row, column int) *Game {
+			g, err := ParseGame(diagram, color)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Move(row, column, color)).To(Succeed())
+			return g
+		}
+		It("captures stone in the corner", func() {
+			g := play(`
+				X O .
+				. . .
+				. . .
+			`, White, 1, 0)
+			Expect(g.board.rows()).To(Equal([]string{".O.", "O..", "..."}))
+			Expect(g.Prisoners(White)).To(Equal(1))
+		})
+		It("captures group after its liberties were filled one by one", func() {
+			g, err := ParseGame(`
+				. . . . .
+				. X X . .
+				. . . . .
+				. . . . .
+				. . . . .
+			`, White)
+			Expect(err).ToNot(HaveOccurred())
+			moves := [][2]int{{0, 1}, {4, 4}, {0, 2}, {4, 3}, {1, 0}, {4, 2},
+				{1, 3}, {4, 1}, {2, 1}, {4, 0}, {2, 2}}
+			for i, m := range moves {
+				Expect(g.Move(m[0], m[1], g.MoveColor())).To(Succeed(), "move %d", i)
+			}
+			Expect(g.board.rows()[1]).To(Equal("O..O."))
+			Expect(g.Prisoners(White)).To(Equal(2))
+		})
+		It("forbids suicide", func() {
+			g, err := ParseGame(`
+				. X .
+				X . .
+				. . .
+			`, White)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Move(0, 0, White)).ToNot(Succeed())
+		})
+		It("forbids immediate ko recapture", func() {
+			g := play(`
+				. X O .
+				X O . O
+				. X O .
+				. . . .
+			`, Black, 1, 2)
+			Expect(g.board.rows()[1]).To(Equal("X.XO"))
+			Expect(g.Move(1, 1, White)).ToNot(Succeed())
+			Expect(g.Move(3, 3, White)).To(Succeed())
+			Expect(g.Move(3, 0, Black)).To(Succeed())
+			Expect(g.Move(1, 1, White)).To(Succeed())
+		})
+	})
+})
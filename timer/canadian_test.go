@@ -0,0 +1,60 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Canadian", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when parameters are validated", func() {
+		It("should require byo-yomi, moves and periods", func() {
+			expectValidation([]Parameters{
+				{System: Canadian, Base: 600 * time.Second, ByoYomi: 300 * time.Second, Moves: 25, Periods: 1},
+				{System: Canadian, ByoYomi: 300 * time.Second, Moves: 10, Periods: 3},
+			}, []Parameters{
+				{System: Canadian, Base: 600 * time.Second, Moves: 25, Periods: 1},
+				{System: Canadian, Base: 600 * time.Second, ByoYomi: 300 * time.Second, Periods: 1},
+				{System: Canadian, Base: 600 * time.Second, ByoYomi: 300 * time.Second, Moves: 25},
+				{System: Canadian, Base: -1, ByoYomi: 300 * time.Second, Moves: 25, Periods: 1},
+			})
+		})
+	})
+	Context("when running", func() {
+		var t *Timer
+		BeforeEach(func() {
+			t = fx.timer(Parameters{System: Canadian, Base: 10 * time.Second, ByoYomi: 10 * time.Second, Moves: 3, Periods: 2})
+		})
+		It("should give a new block after stones are played in time", func() {
+			// Move, which runs out main time, is the first stone of the block.
+			Expect(fx.move(t, 10*time.Second)).To(Equal(10 * time.Second))
+			Expect(fx.move(t, 4*time.Second)).To(Equal(6 * time.Second))
+			Expect(fx.move(t, 5*time.Second)).To(Equal(10 * time.Second))
+			_, periods := t.Remaining()
+			Expect(periods).To(Equal(2))
+			Expect(fx.periodsOver).To(Equal(0))
+		})
+		It("should take period when stones are not played in time", func() {
+			fx.move(t, 10*time.Second)
+			fx.move(t, 6*time.Second)
+			t.Switch()
+			fx.clock.Advance(4 * time.Second)
+			Expect(fx.periodsOver).To(Equal(1))
+			t.Switch()
+			remaining, periods := t.Remaining()
+			Expect(remaining).To(Equal(10 * time.Second))
+			Expect(periods).To(Equal(1))
+			// New block needs all three stones again.
+			fx.move(t, 3*time.Second)
+			t.Switch()
+			fx.clock.Advance(7 * time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package timer
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells time and calls functions after duration as time package
+// does. Timer uses real clock unless other is given, e.g. FakeClock in
+// tests.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Alarm
+}
+
+// Alarm is pending call of Clock.AfterFunc. Stop reports whether the
+// call is cancelled before it is made.
+type Alarm interface {
+	Stop() bool
+}
+
+// RealClock is clock of time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Alarm {
+	return time.AfterFunc(d, f)
+}
+
+// FakeClock is clock, which time moves only by Advance. Functions are
+// called by Advance on the caller goroutine.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	alarms []*fakeAlarm
+}
+
+type fakeAlarm struct {
+	clock *FakeClock
+	at    time.Time
+	f     func()
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Alarm {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a := &fakeAlarm{clock: c, at: c.now.Add(d), f: f}
+	c.alarms = append(c.alarms, a)
+	return a
+}
+
+// Advance moves time forward by d and calls functions, which time has
+// come, in time order. Time is at the alarm time during its call, so
+// functions set up by a call are called too if they are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for {
+		next := -1
+		for i, a := range c.alarms {
+			if !a.at.After(end) && (next < 0 || a.at.Before(c.alarms[next].at)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		a := c.alarms[next]
+		c.alarms = append(c.alarms[:next], c.alarms[next+1:]...)
+		if a.at.After(c.now) {
+			c.now = a.at
+		}
+		c.mu.Unlock()
+		a.f()
+		c.mu.Lock()
+	}
+	c.now = end
+	c.mu.Unlock()
+}
+
+func (a *fakeAlarm) Stop() bool {
+	c := a.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.alarms {
+		if pending == a {
+			c.alarms = append(c.alarms[:i], c.alarms[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
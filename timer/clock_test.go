@@ -0,0 +1,43 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("FakeClock", func() {
+	var clock *FakeClock
+	BeforeEach(func() {
+		clock = NewFakeClock(time.Unix(0, 0))
+	})
+	It("moves only by advance", func() {
+		Expect(clock.Now()).To(Equal(time.Unix(0, 0)))
+		clock.Advance(time.Minute)
+		Expect(clock.Now()).To(Equal(time.Unix(60, 0)))
+	})
+	It("calls functions in time order at their time", func() {
+		var calls []time.Time
+		call := func() { calls = append(calls, clock.Now()) }
+		clock.AfterFunc(2*time.Second, call)
+		clock.AfterFunc(time.Second, func() {
+			call()
+			clock.AfterFunc(500*time.Millisecond, call)
+		})
+		clock.AfterFunc(3*time.Second, call)
+		clock.Advance(2 * time.Second)
+		Expect(calls).To(Equal([]time.Time{
+			time.Unix(1, 0), time.Unix(1, 5e8), time.Unix(2, 0),
+		}))
+	})
+	It("doesn't call stopped functions", func() {
+		called := false
+		alarm := clock.AfterFunc(time.Second, func() { called = true })
+		Expect(alarm.Stop()).To(BeTrue())
+		Expect(alarm.Stop()).To(BeFalse())
+		clock.Advance(time.Second)
+		Expect(called).To(BeFalse())
+	})
+})
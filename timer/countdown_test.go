@@ -0,0 +1,64 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Countdown", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when running", func() {
+		It("should announce main time and each period", func() {
+			t := fx.timer(Parameters{Base: 30 * time.Second, ByoYomi: 10 * time.Second, Periods: 2, Moves: 1},
+				2*time.Second, 20*time.Second, time.Second, 10*time.Second)
+			t.Switch()
+			fx.clock.Advance(time.Minute)
+			Expect(fx.countdowns).To(Equal([]countdown{
+				{10 * time.Second, 20 * time.Second},
+				{20 * time.Second, 10 * time.Second},
+				{28 * time.Second, 2 * time.Second},
+				{29 * time.Second, time.Second},
+				{38 * time.Second, 2 * time.Second},
+				{39 * time.Second, time.Second},
+				{48 * time.Second, 2 * time.Second},
+				{49 * time.Second, time.Second},
+			}))
+		})
+		It("should continue after switch and pause", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second}, 5*time.Second, 3*time.Second)
+			t.Switch()
+			fx.clock.Advance(4 * time.Second)
+			t.Switch()
+			fx.clock.Advance(time.Minute)
+			Expect(fx.countdowns).To(BeEmpty())
+			t.Switch()
+			fx.clock.Advance(1500 * time.Millisecond)
+			Expect(t.Pause()).To(Succeed())
+			fx.clock.Advance(time.Minute)
+			Expect(fx.countdowns).To(Equal([]countdown{{65 * time.Second, 5 * time.Second}}))
+			Expect(t.Resume()).To(Succeed())
+			fx.clock.Advance(time.Minute)
+			Expect(fx.countdowns).To(Equal([]countdown{
+				{65 * time.Second, 5 * time.Second},
+				{127 * time.Second, 3 * time.Second},
+			}))
+		})
+		It("should count simple delay before main time", func() {
+			fx.timer(Parameters{System: SimpleDelay, Base: 10 * time.Second, Delay: 5 * time.Second}, 3*time.Second).Switch()
+			fx.clock.Advance(time.Minute)
+			Expect(fx.countdowns).To(Equal([]countdown{{12 * time.Second, 3 * time.Second}}))
+		})
+		It("should skip thresholds passed before start", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second}, 20*time.Second, 10*time.Second, 5*time.Second)
+			t.Switch()
+			fx.clock.Advance(time.Minute)
+			Expect(fx.countdowns).To(Equal([]countdown{{5 * time.Second, 5 * time.Second}}))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Delay", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when parameters are validated", func() {
+		It("should require base and delay only", func() {
+			expectValidation([]Parameters{
+				{System: SimpleDelay, Base: 60 * time.Second, Delay: 5 * time.Second},
+				{System: Bronstein, Base: 60 * time.Second, Delay: 5 * time.Second},
+			}, []Parameters{
+				{System: SimpleDelay, Base: 60 * time.Second},
+				{System: Bronstein, Delay: 5 * time.Second},
+				{System: Bronstein, Base: 60 * time.Second, Delay: 5 * time.Second, ByoYomi: 30 * time.Second, Periods: 1, Moves: 1},
+				{System: SimpleDelay, Base: 60 * time.Second, Delay: 5 * time.Second, Increment: 5 * time.Second},
+				{System: Fischer, Base: 60 * time.Second, Increment: 5 * time.Second, Delay: 5 * time.Second},
+				{Base: 60 * time.Second, Delay: 5 * time.Second},
+			})
+		})
+	})
+	create := func(system System) *Timer {
+		return fx.timer(Parameters{System: system, Base: 10 * time.Second, Delay: 3 * time.Second})
+	}
+	for _, system := range []System{SimpleDelay, Bronstein} {
+		system := system
+		Context("when "+system.String()+" is running", func() {
+			It("should keep main time for moves faster than delay", func() {
+				t := create(system)
+				Expect(fx.move(t, 2*time.Second)).To(Equal(10 * time.Second))
+				Expect(fx.move(t, 3*time.Second)).To(Equal(10 * time.Second))
+			})
+			It("should take time over delay for slower moves", func() {
+				t := create(system)
+				Expect(fx.move(t, 5*time.Second)).To(Equal(8 * time.Second))
+				Expect(fx.move(t, 4*time.Second)).To(Equal(7 * time.Second))
+				Expect(fx.over).To(BeFalse())
+			})
+		})
+	}
+	Context("when time runs out", func() {
+		It("should be over after main time and delay in simple delay", func() {
+			t := create(SimpleDelay)
+			t.Switch()
+			fx.clock.Advance(12 * time.Second)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+		It("should be over after main time in Bronstein", func() {
+			t := create(Bronstein)
+			t.Switch()
+			fx.clock.Advance(9 * time.Second)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+	})
+})
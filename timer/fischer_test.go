@@ -0,0 +1,70 @@
+package timer_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Fischer", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when parameters are validated", func() {
+		It("should require base and increment only", func() {
+			expectValidation([]Parameters{
+				{System: Fischer, Base: 60 * time.Second, Increment: 10 * time.Second},
+				{System: Fischer, Base: 60 * time.Second, Increment: 10 * time.Second, Cap: 60 * time.Second},
+			}, []Parameters{
+				{System: Fischer, Increment: 10 * time.Second},
+				{System: Fischer, Base: 60 * time.Second},
+				{System: Fischer, Base: 60 * time.Second, Increment: 10 * time.Second, ByoYomi: 30 * time.Second, Periods: 1, Moves: 1},
+				{System: Fischer, Base: 60 * time.Second, Increment: 10 * time.Second, Cap: 30 * time.Second},
+				{System: Fischer, Base: 60 * time.Second, Increment: 10 * time.Second, Cap: -1},
+				{Base: 60 * time.Second, Increment: 10 * time.Second},
+			})
+		})
+	})
+	Context("when written in JSON", func() {
+		It("should have system name", func() {
+			p := Parameters{System: Fischer, Base: 300 * time.Second, Increment: 5 * time.Second, Cap: 600 * time.Second}
+			data, err := json.Marshal(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(
+				`{"system":"fischer","base":300,"byoYomi":0,"periods":0,"moves":0,"increment":5,"cap":600}`))
+			var restored Parameters
+			Expect(json.Unmarshal(data, &restored)).To(Succeed())
+			Expect(restored).To(Equal(p))
+			var legacy Parameters
+			Expect(json.Unmarshal([]byte(`{"base":60}`), &legacy)).To(Succeed())
+			Expect(legacy.System).To(Equal(ByoYomi))
+			Expect(json.Unmarshal([]byte(`{"system":"bullet"}`), &restored)).ToNot(Succeed())
+		})
+	})
+	Context("when running", func() {
+		parameters := Parameters{System: Fischer, Base: 10 * time.Second, Increment: 5 * time.Second}
+		It("should add increment after each move", func() {
+			t := fx.timer(parameters)
+			Expect(fx.move(t, 2*time.Second)).To(Equal(13 * time.Second))
+			Expect(fx.move(t, 12*time.Second)).To(Equal(6 * time.Second))
+			Expect(fx.over).To(BeFalse())
+		})
+		It("should not grow main time above cap", func() {
+			capped := parameters
+			capped.Cap = 12 * time.Second
+			Expect(fx.move(fx.timer(capped), time.Second)).To(Equal(12 * time.Second))
+		})
+		It("should be over when main time runs out", func() {
+			t := fx.timer(parameters)
+			t.Switch()
+			fx.clock.Advance(10 * time.Second)
+			Expect(fx.over).To(BeTrue())
+			t.Switch()
+			Expect(remaining(t)).To(Equal(time.Duration(0)))
+		})
+	})
+})
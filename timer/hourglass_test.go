@@ -0,0 +1,70 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Hourglass", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	parameters := Parameters{System: Hourglass, Base: 10 * time.Second}
+	Context("when parameters are validated", func() {
+		It("should require linked timers of both players", func() {
+			Expect(parameters.Validate()).To(Succeed())
+			expectValidation(nil, []Parameters{
+				{System: Hourglass},
+				{System: Hourglass, Base: 10 * time.Second, ByoYomi: 10 * time.Second, Periods: 1, Moves: 1},
+			})
+			_, err := NewTimer(parameters, Callbacks{})
+			Expect(err).To(HaveOccurred())
+			_, _, err = NewAsymmetricTimerPair(parameters, Parameters{Base: 10 * time.Second}, Callbacks{}, Callbacks{}, fx.clock)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("when running", func() {
+		var (
+			first      *Timer
+			second     *Timer
+			firstOver  bool
+			secondOver bool
+		)
+		BeforeEach(func() {
+			firstOver, secondOver = false, false
+			var err error
+			first, second, err = NewTimerPair(parameters,
+				Callbacks{OnOver: func() { firstOver = true }},
+				Callbacks{OnOver: func() { secondOver = true }},
+				fx.clock)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should add time used by player to the opponent", func() {
+			Expect(fx.move(first, 3*time.Second)).To(Equal(7 * time.Second))
+			Expect(remaining(second)).To(Equal(13 * time.Second))
+			Expect(fx.move(second, 5*time.Second)).To(Equal(8 * time.Second))
+			Expect(remaining(first)).To(Equal(12 * time.Second))
+		})
+		It("should extend running opponent", func() {
+			second.Switch()
+			first.Switch()
+			fx.clock.Advance(2 * time.Second)
+			first.Switch()
+			// Second has 8 seconds left and 2 seconds used by first.
+			fx.clock.Advance(9 * time.Second)
+			Expect(secondOver).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(secondOver).To(BeTrue())
+		})
+		It("should fall flag when time runs out", func() {
+			first.Switch()
+			fx.clock.Advance(10 * time.Second)
+			Expect(firstOver).To(BeTrue())
+			Expect(remaining(second)).To(Equal(10 * time.Second))
+		})
+	})
+})
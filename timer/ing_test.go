@@ -0,0 +1,53 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Ing", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	parameters := Parameters{System: Ing, Base: 10 * time.Second, ByoYomi: 20 * time.Second, Periods: 3}
+	Context("when parameters are validated", func() {
+		It("should require base, byo-yomi and up to three periods", func() {
+			expectValidation([]Parameters{
+				parameters,
+				{System: Ing, Base: time.Second, ByoYomi: time.Second, Periods: 1},
+			}, []Parameters{
+				{System: Ing, ByoYomi: time.Second, Periods: 3},
+				{System: Ing, Base: time.Second, Periods: 3},
+				{System: Ing, Base: time.Second, ByoYomi: time.Second},
+				{System: Ing, Base: time.Second, ByoYomi: time.Second, Periods: 4},
+				{System: Ing, Base: time.Second, ByoYomi: time.Second, Periods: 3, Moves: 1},
+			})
+		})
+	})
+	Context("when running", func() {
+		It("should keep period time between moves", func() {
+			t := fx.timer(parameters)
+			fx.move(t, 15*time.Second)
+			Expect(parameters.PeriodsStarted(t.State())).To(Equal(1))
+			fx.move(t, 10*time.Second)
+			Expect(t.State()).To(Equal(State{ByoYomi: 5 * time.Second, Periods: 3, LastMove: 10 * time.Second, Turns: 2}))
+			t.Switch()
+			fx.clock.Advance(10 * time.Second)
+			Expect(fx.periodsOver).To(Equal(1))
+			Expect(parameters.PeriodsStarted(t.State())).To(Equal(2))
+			// 15 seconds of the second period and the last period are left.
+			fx.clock.Advance(34 * time.Second)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(fx.over).To(BeTrue())
+			Expect(parameters.PeriodsStarted(t.State())).To(Equal(3))
+		})
+		It("should start no period in main time", func() {
+			Expect(parameters.PeriodsStarted(InitialState(parameters))).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,184 @@
+package timer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type parametersJSON struct {
+	System    System   `json:"system,omitempty"`
+	Base      Duration `json:"base"`
+	ByoYomi   Duration `json:"byoYomi"`
+	Periods   int      `json:"periods"`
+	Moves     int      `json:"moves"`
+	Increment Duration `json:"increment,omitempty"`
+	Cap       Duration `json:"cap,omitempty"`
+	Delay     Duration `json:"delay,omitempty"`
+	Lag       *lagJSON `json:"lag,omitempty"`
+	MoveLimit Duration `json:"moveLimit,omitempty"`
+}
+
+type lagJSON struct {
+	PerMove Duration `json:"perMove"`
+	PerGame Duration `json:"perGame,omitempty"`
+}
+
+func (p Parameters) MarshalJSON() ([]byte, error) {
+	pj := parametersJSON{
+		System:    p.System,
+		Base:      Duration(p.Base),
+		ByoYomi:   Duration(p.ByoYomi),
+		Periods:   p.Periods,
+		Moves:     p.Moves,
+		Increment: Duration(p.Increment),
+		Cap:       Duration(p.Cap),
+		Delay:     Duration(p.Delay),
+		MoveLimit: Duration(p.MoveLimit),
+	}
+	if p.Lag != nil {
+		pj.Lag = &lagJSON{PerMove: Duration(p.Lag.PerMove), PerGame: Duration(p.Lag.PerGame)}
+	}
+	return json.Marshal(pj)
+}
+
+// UnmarshalJSON reads durations written as seconds, e.g. 600 or 2.5,
+// or as duration strings, e.g. "1m30s" or "2500ms".
+func (p *Parameters) UnmarshalJSON(data []byte) error {
+	var pj parametersJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+	*p = Parameters{
+		System:    pj.System,
+		Base:      time.Duration(pj.Base),
+		ByoYomi:   time.Duration(pj.ByoYomi),
+		Periods:   pj.Periods,
+		Moves:     pj.Moves,
+		Increment: time.Duration(pj.Increment),
+		Cap:       time.Duration(pj.Cap),
+		Delay:     time.Duration(pj.Delay),
+		MoveLimit: time.Duration(pj.MoveLimit),
+	}
+	if pj.Lag != nil {
+		p.Lag = &Lag{PerMove: time.Duration(pj.Lag.PerMove), PerGame: time.Duration(pj.Lag.PerGame)}
+	}
+	return nil
+}
+
+// Duration is time.Duration in JSON of time settings and clock states.
+// Whole seconds are written as number, so parameters with integer
+// seconds are read by older versions, other durations as string, e.g.
+// "2.5s". Both forms are read.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	if time.Duration(d)%time.Second == 0 {
+		return []byte(strconv.FormatInt(int64(time.Duration(d)/time.Second), 10)), nil
+	}
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		*d = Duration(v)
+		return nil
+	}
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = Duration(math.Round(seconds * float64(time.Second)))
+	return nil
+}
+
+type stateJSON struct {
+	Base       Duration    `json:"base"`
+	ByoYomi    Duration    `json:"byoYomi"`
+	Periods    int         `json:"periods"`
+	Moves      int         `json:"moves"`
+	Running    bool        `json:"running,omitempty"`
+	Paused     bool        `json:"paused,omitempty"`
+	Over       bool        `json:"over,omitempty"`
+	LagUsed    Duration    `json:"lagUsed,omitempty"`
+	DelayLeft  Duration    `json:"delayLeft,omitempty"`
+	LastMove   Duration    `json:"lastMove,omitempty"`
+	Turns      int         `json:"turns,omitempty"`
+	LagCredits []LagCredit `json:"lagCredits,omitempty"`
+}
+
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stateJSON{
+		Base:       Duration(s.Base),
+		ByoYomi:    Duration(s.ByoYomi),
+		Periods:    s.Periods,
+		Moves:      s.Moves,
+		Running:    s.Running,
+		Paused:     s.Paused,
+		Over:       s.Over,
+		LagUsed:    Duration(s.LagUsed),
+		DelayLeft:  Duration(s.DelayLeft),
+		LastMove:   Duration(s.LastMove),
+		Turns:      s.Turns,
+		LagCredits: s.LagCredits,
+	})
+}
+
+func (s *State) UnmarshalJSON(data []byte) error {
+	var sj stateJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+	*s = State{
+		Base:       time.Duration(sj.Base),
+		ByoYomi:    time.Duration(sj.ByoYomi),
+		Periods:    sj.Periods,
+		Moves:      sj.Moves,
+		Running:    sj.Running,
+		Paused:     sj.Paused,
+		Over:       sj.Over,
+		LagUsed:    time.Duration(sj.LagUsed),
+		DelayLeft:  time.Duration(sj.DelayLeft),
+		LastMove:   time.Duration(sj.LastMove),
+		Turns:      sj.Turns,
+		LagCredits: sj.LagCredits,
+	}
+	return nil
+}
+
+type lagCreditJSON struct {
+	Move     int      `json:"move"`
+	Elapsed  Duration `json:"elapsed"`
+	Think    Duration `json:"think"`
+	Credited Duration `json:"credited"`
+}
+
+func (c LagCredit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lagCreditJSON{
+		Move:     c.Move,
+		Elapsed:  Duration(c.Elapsed),
+		Think:    Duration(c.Think),
+		Credited: Duration(c.Credited),
+	})
+}
+
+func (c *LagCredit) UnmarshalJSON(data []byte) error {
+	var cj lagCreditJSON
+	if err := json.Unmarshal(data, &cj); err != nil {
+		return err
+	}
+	*c = LagCredit{
+		Move:     cj.Move,
+		Elapsed:  time.Duration(cj.Elapsed),
+		Think:    time.Duration(cj.Think),
+		Credited: time.Duration(cj.Credited),
+	}
+	return nil
+}
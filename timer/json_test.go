@@ -0,0 +1,58 @@
+package timer_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Parameters JSON", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when written", func() {
+		It("should have whole seconds as numbers", func() {
+			data, err := json.Marshal(Parameters{Base: 10 * time.Minute, ByoYomi: 30 * time.Second, Periods: 5, Moves: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"base":600,"byoYomi":30,"periods":5,"moves":1}`))
+		})
+		It("should have fractions of seconds as duration strings", func() {
+			p := Parameters{ByoYomi: 2500 * time.Millisecond, Periods: 1, Moves: 1,
+				Lag: &Lag{PerMove: 300 * time.Millisecond, PerGame: 5 * time.Second}}
+			data, err := json.Marshal(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(
+				`{"base":0,"byoYomi":"2.5s","periods":1,"moves":1,"lag":{"perMove":"300ms","perGame":5}}`))
+			var restored Parameters
+			Expect(json.Unmarshal(data, &restored)).To(Succeed())
+			Expect(restored).To(Equal(p))
+		})
+	})
+	Context("when read", func() {
+		It("should accept seconds and duration strings", func() {
+			var p Parameters
+			Expect(json.Unmarshal([]byte(`{"base":"1m30s","byoYomi":0.3,"periods":1,"moves":1}`), &p)).To(Succeed())
+			Expect(p.Base).To(Equal(90 * time.Second))
+			Expect(p.ByoYomi).To(Equal(300 * time.Millisecond))
+			Expect(json.Unmarshal([]byte(`{"base":"2500ms"}`), &p)).To(Succeed())
+			Expect(p.Base).To(Equal(2500 * time.Millisecond))
+			Expect(json.Unmarshal([]byte(`{"base":"long"}`), &p)).ToNot(Succeed())
+			Expect(json.Unmarshal([]byte(`{"base":true}`), &p)).ToNot(Succeed())
+		})
+		It("should run sub-second byo-yomi", func() {
+			t := fx.timer(Parameters{ByoYomi: 2500 * time.Millisecond, Periods: 1, Moves: 1})
+			t.Switch()
+			fx.clock.Advance(2400 * time.Millisecond)
+			t.Switch()
+			t.Switch()
+			fx.clock.Advance(2499 * time.Millisecond)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Millisecond)
+			Expect(fx.over).To(BeTrue())
+		})
+	})
+})
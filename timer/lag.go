@@ -0,0 +1,89 @@
+package timer
+
+import (
+	"errors"
+	"time"
+)
+
+// Lag is allowance of network transit time credited back to players of
+// online games. Zero per game allowance is no limit.
+type Lag struct {
+	PerMove time.Duration
+	PerGame time.Duration
+}
+
+func (l Lag) validate() error {
+	if l.PerMove < 0 || l.PerGame < 0 {
+		return errors.New("lag allowance should be greater or equal to zero")
+	}
+	return nil
+}
+
+// LagCredit is audit record of the move with reported think time. Move
+// is number of the move timed by timer since its creation, elapsed is
+// time measured by timer, credited is part of it given back.
+type LagCredit struct {
+	Move     int
+	Elapsed  time.Duration
+	Think    time.Duration
+	Credited time.Duration
+}
+
+// SwitchWithThinkTime stops running timer as Switch, but credits back
+// difference between measured time and think time reported by client,
+// up to lag allowance of parameters. Stopped timer is started as by
+// Switch. Lag is credited only for moves arriving before timer
+// expiration.
+func (t *Timer) SwitchWithThinkTime(think time.Duration) {
+	t.toggle(think, true)
+}
+
+// LagCredits returns audit records of moves with reported think time.
+func (t *Timer) LagCredits() []LagCredit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	credits := make([]LagCredit, len(t.lagCredits))
+	copy(credits, t.lagCredits)
+	return credits
+}
+
+// copyCredits returns copy of credits, nil when there are none.
+func copyCredits(credits []LagCredit) []LagCredit {
+	if len(credits) == 0 {
+		return nil
+	}
+	return append([]LagCredit(nil), credits...)
+}
+
+// creditLag moves start of the running move forward by transit time of
+// the move within allowance. Time of main time or periods already over
+// isn't given back.
+func (t *Timer) creditLag(think time.Duration) {
+	lag := t.parameters.Lag
+	if lag == nil {
+		return
+	}
+	if think < 0 {
+		think = 0
+	}
+	elapsed := t.clock.Now().Sub(t.moveStartedAt)
+	credit := elapsed - think
+	if credit < 0 {
+		credit = 0
+	}
+	if credit > lag.PerMove {
+		credit = lag.PerMove
+	}
+	if left := lag.PerGame - t.lagUsed; lag.PerGame > 0 && credit > left {
+		credit = left
+	}
+	if running := t.clock.Now().Sub(t.startedAt); credit > running {
+		credit = running
+	}
+	t.lagUsed += credit
+	t.startedAt = t.startedAt.Add(credit)
+	t.moveStartedAt = t.moveStartedAt.Add(credit)
+	t.lagCredits = append(t.lagCredits, LagCredit{
+		Move: t.turns, Elapsed: elapsed, Think: think, Credited: credit,
+	})
+}
@@ -0,0 +1,107 @@
+package timer_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Lag compensation", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	create := func(lag *Lag) *Timer {
+		return fx.timer(Parameters{Base: 60 * time.Second, Lag: lag})
+	}
+	// moveWithThink runs timer for a move taking elapsed, of which think
+	// is reported by the player, and returns remaining time.
+	moveWithThink := func(t *Timer, elapsed time.Duration, think time.Duration) time.Duration {
+		t.Switch()
+		fx.clock.Advance(elapsed)
+		t.SwitchWithThinkTime(think)
+		return remaining(t)
+	}
+	Context("when parameters are validated", func() {
+		It("should reject negative allowance", func() {
+			expectValidation(nil, []Parameters{{Base: 60 * time.Second, Lag: &Lag{PerMove: -time.Second}}})
+		})
+	})
+	Context("when written in JSON", func() {
+		It("should have credit durations in seconds", func() {
+			c := LagCredit{Move: 2, Elapsed: 5 * time.Second, Think: 2 * time.Second, Credited: 500 * time.Millisecond}
+			data, err := json.Marshal(c)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"move":2,"elapsed":5,"think":2,"credited":"500ms"}`))
+			var restored LagCredit
+			Expect(json.Unmarshal(data, &restored)).To(Succeed())
+			Expect(restored).To(Equal(c))
+		})
+	})
+	Context("when player reports think time", func() {
+		It("should credit transit time up to per move allowance", func() {
+			t := create(&Lag{PerMove: time.Second})
+			Expect(moveWithThink(t, 3*time.Second, 2500*time.Millisecond)).To(Equal(57500 * time.Millisecond))
+			Expect(moveWithThink(t, 5*time.Second, 2*time.Second)).To(Equal(53500 * time.Millisecond))
+			Expect(moveWithThink(t, 2*time.Second, 3*time.Second)).To(Equal(51500 * time.Millisecond))
+			Expect(t.LagCredits()).To(Equal([]LagCredit{
+				{Move: 1, Elapsed: 3 * time.Second, Think: 2500 * time.Millisecond, Credited: 500 * time.Millisecond},
+				{Move: 2, Elapsed: 5 * time.Second, Think: 2 * time.Second, Credited: time.Second},
+				{Move: 3, Elapsed: 2 * time.Second, Think: 3 * time.Second},
+			}))
+		})
+		It("should limit credit by per game allowance", func() {
+			t := create(&Lag{PerMove: time.Second, PerGame: 1500 * time.Millisecond})
+			Expect(moveWithThink(t, 2*time.Second, 0)).To(Equal(59 * time.Second))
+			Expect(moveWithThink(t, 2*time.Second, 0)).To(Equal(57500 * time.Millisecond))
+			Expect(moveWithThink(t, 2*time.Second, 0)).To(Equal(55500 * time.Millisecond))
+			Expect(t.State().LagUsed).To(Equal(1500 * time.Millisecond))
+
+			restored := create(&Lag{PerMove: time.Second, PerGame: 1500 * time.Millisecond})
+			Expect(restored.Restore(t.State())).To(Succeed())
+			Expect(moveWithThink(restored, 2*time.Second, 0)).To(Equal(53500 * time.Millisecond))
+		})
+		It("should credit whole move after main time is over", func() {
+			p := Parameters{System: Canadian, Base: 10 * time.Second, ByoYomi: 30 * time.Second, Periods: 1, Moves: 5,
+				Lag: &Lag{PerMove: 2 * time.Second}}
+			t := fx.timer(p)
+			Expect(moveWithThink(t, 12*time.Second, 11*time.Second)).To(Equal(29 * time.Second))
+			Expect(t.LagCredits()).To(Equal([]LagCredit{
+				{Move: 1, Elapsed: 12 * time.Second, Think: 11 * time.Second, Credited: time.Second},
+			}))
+			// Main time already over is not given back.
+			t = fx.timer(p)
+			Expect(moveWithThink(t, 10500*time.Millisecond, 9*time.Second)).To(Equal(30 * time.Second))
+			Expect(t.LagCredits()).To(Equal([]LagCredit{
+				{Move: 1, Elapsed: 10500 * time.Millisecond, Think: 9 * time.Second, Credited: 500 * time.Millisecond},
+			}))
+		})
+		It("should keep credits over restore", func() {
+			t := create(&Lag{PerMove: time.Second})
+			moveWithThink(t, 3*time.Second, 2*time.Second)
+			data, err := json.Marshal(t.State())
+			Expect(err).ToNot(HaveOccurred())
+			var s State
+			Expect(json.Unmarshal(data, &s)).To(Succeed())
+			restored := create(&Lag{PerMove: time.Second})
+			Expect(restored.Restore(s)).To(Succeed())
+			Expect(restored.LagCredits()).To(Equal(t.LagCredits()))
+			moveWithThink(restored, 2*time.Second, 2*time.Second)
+			Expect(restored.LagCredits()[1].Move).To(Equal(2))
+		})
+		It("should not credit without allowance or report", func() {
+			t := create(nil)
+			Expect(moveWithThink(t, 2*time.Second, 0)).To(Equal(58 * time.Second))
+			Expect(t.LagCredits()).To(BeEmpty())
+			t = create(&Lag{PerMove: time.Second})
+			t.Switch()
+			fx.clock.Advance(2 * time.Second)
+			t.Switch()
+			Expect(remaining(t)).To(Equal(58 * time.Second))
+			Expect(t.LagCredits()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package timer_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Absolute time and move limit", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when parameters are validated", func() {
+		It("should require base of absolute time and positive move limit", func() {
+			expectValidation([]Parameters{
+				{System: Absolute, Base: time.Second},
+				{System: Absolute, Base: time.Second, MoveLimit: time.Second},
+			}, []Parameters{
+				{System: Absolute},
+				{System: Absolute, Base: time.Second, ByoYomi: time.Second, Periods: 1, Moves: 1},
+				{System: Absolute, Base: time.Second, MoveLimit: -time.Second},
+			})
+		})
+	})
+	Context("when running", func() {
+		It("should end absolute time without overtime", func() {
+			t := fx.timer(Parameters{System: Absolute, Base: 2 * time.Second})
+			t.Switch()
+			fx.clock.Advance(time.Second)
+			t.Switch()
+			t.Switch()
+			fx.clock.Advance(999 * time.Millisecond)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Millisecond)
+			Expect(fx.over).To(BeTrue())
+			Expect(t.State().Over).To(BeTrue())
+		})
+		It("should end move over limit regardless of time left", func() {
+			t := fx.timer(Parameters{System: Fischer, Base: time.Hour, Increment: time.Second, MoveLimit: 10 * time.Second})
+			t.Switch()
+			fx.clock.Advance(9 * time.Second)
+			t.Switch()
+			t.Switch()
+			fx.clock.Advance(4 * time.Second)
+			Expect(t.Pause()).To(Succeed())
+			fx.clock.Advance(time.Minute)
+			Expect(fx.over).To(BeFalse())
+			Expect(t.Resume()).To(Succeed())
+			fx.clock.Advance(5999 * time.Millisecond)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Millisecond)
+			Expect(fx.over).To(BeTrue())
+			t.Switch()
+			Expect(t.State().Over).To(BeTrue())
+		})
+		It("should ignore limit alarm of the move before pause", func() {
+			clock := &lateClock{FakeClock: fx.clock}
+			t, err := NewTimerWithClock(Parameters{System: Fischer, Base: time.Hour, Increment: time.Second,
+				MoveLimit: 10 * time.Second}, fx.callbacks(), clock)
+			Expect(err).ToNot(HaveOccurred())
+			t.Switch()
+			clock.Advance(10 * time.Second)
+			// Pause gets the lock before the fired alarm, the move is
+			// resumed before the alarm is handled.
+			Expect(t.Pause()).To(Succeed())
+			Expect(t.Resume()).To(Succeed())
+			clock.fire()
+			Expect(fx.over).To(BeFalse())
+			Expect(t.State().Over).To(BeFalse())
+			// Limit alarm set by resume still ends the move.
+			clock.Advance(0)
+			clock.fire()
+			Expect(fx.over).To(BeTrue())
+		})
+	})
+	Context("when written in JSON", func() {
+		It("should keep move limit", func() {
+			p := Parameters{System: Absolute, Base: time.Minute, MoveLimit: 1500 * time.Millisecond}
+			data, err := json.Marshal(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"system":"absolute","base":60,"byoYomi":0,"periods":0,"moves":0,"moveLimit":"1.5s"}`))
+			var restored Parameters
+			Expect(json.Unmarshal(data, &restored)).To(Succeed())
+			Expect(restored).To(Equal(p))
+		})
+	})
+})
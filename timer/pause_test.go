@@ -0,0 +1,98 @@
+package timer_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("Pause", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	Context("when paused", func() {
+		It("should freeze running timer", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second})
+			t.Switch()
+			fx.clock.Advance(4 * time.Second)
+			Expect(t.Pause()).To(Succeed())
+			fx.clock.Advance(time.Hour)
+			Expect(fx.over).To(BeFalse())
+			Expect(t.State()).To(Equal(State{Base: 6 * time.Second, Paused: true}))
+
+			Expect(t.Resume()).To(Succeed())
+			Expect(t.State()).To(Equal(State{Base: 6 * time.Second, Running: true}))
+			fx.clock.Advance(5 * time.Second)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+		It("should keep delay used before pause", func() {
+			t := fx.timer(Parameters{System: SimpleDelay, Base: 10 * time.Second, Delay: 5 * time.Second})
+			t.Switch()
+			fx.clock.Advance(3 * time.Second)
+			Expect(t.Pause()).To(Succeed())
+			fx.clock.Advance(time.Minute)
+			Expect(t.Resume()).To(Succeed())
+			fx.clock.Advance(4 * time.Second)
+			Expect(remaining(t)).To(Equal(8 * time.Second))
+		})
+		It("should pause byo-yomi period", func() {
+			t := fx.timer(Parameters{ByoYomi: 10 * time.Second, Periods: 1, Moves: 1})
+			t.Switch()
+			fx.clock.Advance(7 * time.Second)
+			Expect(t.Pause()).To(Succeed())
+			fx.clock.Advance(time.Minute)
+			Expect(t.Resume()).To(Succeed())
+			fx.clock.Advance(2 * time.Second)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+		It("should not count pause in move time", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second})
+			t.Switch()
+			fx.clock.Advance(time.Second)
+			Expect(t.Pause()).To(Succeed())
+			fx.clock.Advance(time.Hour)
+			Expect(t.Resume()).To(Succeed())
+			fx.clock.Advance(2 * time.Second)
+			t.Switch()
+			Expect(t.LastMoveTime()).To(Equal(3 * time.Second))
+		})
+		It("should ignore switch", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second})
+			t.Switch()
+			fx.clock.Advance(time.Second)
+			Expect(t.Pause()).To(Succeed())
+			t.Switch()
+			Expect(t.State()).To(Equal(State{Base: 9 * time.Second, Paused: true}))
+		})
+	})
+	Context("when state doesn't allow pause", func() {
+		It("should be error", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second})
+			Expect(t.Pause()).ToNot(Succeed())
+			Expect(t.Resume()).ToNot(Succeed())
+			t.Switch()
+			Expect(t.Resume()).ToNot(Succeed())
+			Expect(t.Pause()).To(Succeed())
+			Expect(t.Pause()).ToNot(Succeed())
+			Expect(t.Restore(State{Base: time.Second})).ToNot(Succeed())
+		})
+	})
+	Context("when restored", func() {
+		It("should stay paused", func() {
+			t := fx.timer(Parameters{Base: 10 * time.Second})
+			Expect(t.Restore(State{Base: 5 * time.Second, Paused: true})).To(Succeed())
+			fx.clock.Advance(time.Minute)
+			Expect(fx.over).To(BeFalse())
+			Expect(t.Resume()).To(Succeed())
+			fx.clock.Advance(5 * time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,144 @@
+package timer
+
+import (
+	"errors"
+	"time"
+)
+
+// State is remaining time of timer. Byo-yomi is time left of the
+// current period, moves are stones left to play in it.
+type State struct {
+	Base    time.Duration
+	ByoYomi time.Duration
+	Periods int
+	Moves   int
+	Running bool
+	Paused  bool
+	Over    bool
+	// LagUsed is lag credited of per game allowance.
+	LagUsed time.Duration
+	// DelayLeft is delay not used yet by the running or paused move in
+	// simple delay and Bronstein systems.
+	DelayLeft time.Duration
+	// LastMove is time taken by the last finished move.
+	LastMove time.Duration
+	// Turns is count of moves timed, lag credits are audit records of
+	// them kept over restore.
+	Turns      int
+	LagCredits []LagCredit
+}
+
+// InitialState returns state of new timer with parameters.
+func InitialState(p Parameters) State {
+	return State{
+		Base:    p.Base,
+		ByoYomi: p.ByoYomi,
+		Periods: p.Periods,
+		Moves:   p.Moves,
+	}
+}
+
+// PeriodsStarted returns count of byo-yomi periods started by timer in
+// state s, e.g. periods costing penalty in Ing system.
+func (p Parameters) PeriodsStarted(s State) int {
+	switch {
+	case p.ByoYomi == 0 || s.Base > 0 && !s.Over:
+		return 0
+	case s.Over:
+		return p.Periods
+	}
+	return p.Periods - s.Periods + 1
+}
+
+// State returns remaining time with running time counted up to now.
+// Main time of simple delay system starts to count down after delay.
+func (t *Timer) State() State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := State{
+		Base:       t.base,
+		ByoYomi:    t.byoYomi,
+		Periods:    t.periods,
+		Moves:      t.moves,
+		Running:    t.timer != nil,
+		Paused:     t.paused,
+		Over:       t.over,
+		LagUsed:    t.lagUsed,
+		LastMove:   t.lastMove,
+		Turns:      t.turns,
+		LagCredits: copyCredits(t.lagCredits),
+	}
+	started := t.timer != nil || t.paused
+	switch {
+	case t.over:
+		s.Base, s.ByoYomi = 0, 0
+	case started && t.mode == base:
+		var free time.Duration
+		if t.parameters.System == SimpleDelay {
+			free = t.parameters.Delay
+		}
+		s.Base = t.elapse(t.base, free)
+		if t.hasDelay() {
+			s.DelayLeft = t.parameters.Delay - t.delayUsed()
+		}
+	case started:
+		s.ByoYomi = t.elapse(t.byoYomi, 0)
+	}
+	return s
+}
+
+// Restore sets remaining time of stopped timer to state, e.g. saved
+// before server restart. Timer is started when state is running, or
+// paused when state is paused.
+func (t *Timer) Restore(s State) error {
+	if s.Base < 0 || s.ByoYomi < 0 || s.Periods < 0 || s.Moves < 0 || s.LagUsed < 0 || s.DelayLeft < 0 ||
+		s.LastMove < 0 || s.Turns < 0 {
+		return errors.New("state shouldn't be negative")
+	}
+	p := t.parameters
+	if s.ByoYomi > p.ByoYomi || s.Periods > p.Periods || s.Moves > p.Moves || s.DelayLeft > p.Delay {
+		return errors.New("state doesn't match parameters")
+	}
+	if !s.Over && s.Base == 0 && (p.ByoYomi == 0 || s.Periods == 0) {
+		return errors.New("state without time should be over")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil || t.paused {
+		return errors.New("timer should be stopped")
+	}
+	t.base, t.byoYomi, t.periods, t.moves = s.Base, s.ByoYomi, s.Periods, s.Moves
+	t.over = s.Over
+	t.lagUsed = s.LagUsed
+	t.lastMove = s.LastMove
+	t.turns = s.Turns
+	t.lagCredits = copyCredits(s.LagCredits)
+	if s.Base > 0 {
+		t.mode = base
+	} else {
+		t.mode = period
+	}
+	// Move restored running or paused continues with delay it already
+	// used, Bronstein main time is restored with the time to refund.
+	var elapsed time.Duration
+	if t.mode == base && t.hasDelay() {
+		elapsed = p.Delay - s.DelayLeft
+		if p.System == Bronstein {
+			t.base += elapsed
+		}
+	}
+	switch {
+	case s.Over:
+	case s.Paused:
+		t.paused = true
+		t.pausedElapsed = elapsed
+		t.pausedAt = t.clock.Now()
+		t.moveStartedAt = t.pausedAt
+	case s.Running:
+		t.moveStartedAt = t.clock.Now()
+		t.switchOn(elapsed)
+		t.startLimit()
+	}
+	return nil
+}
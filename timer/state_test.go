@@ -0,0 +1,143 @@
+package timer_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
+)
+
+var _ = Describe("State", func() {
+	var fx *fixture
+	BeforeEach(func() {
+		fx = newFixture()
+	})
+	parameters := Parameters{System: Canadian, Base: 10 * time.Second, ByoYomi: 20 * time.Second, Moves: 5, Periods: 2}
+	Context("when timer is created", func() {
+		It("should start from parameters", func() {
+			Expect(fx.timer(parameters).State()).To(Equal(InitialState(parameters)))
+			Expect(InitialState(parameters)).To(Equal(State{
+				Base: 10 * time.Second, ByoYomi: 20 * time.Second, Periods: 2, Moves: 5,
+			}))
+		})
+	})
+	Context("when running", func() {
+		It("should count running time live", func() {
+			t := fx.timer(parameters)
+			t.Switch()
+			fx.clock.Advance(3 * time.Second)
+			Expect(t.State()).To(Equal(State{
+				Base: 7 * time.Second, ByoYomi: 20 * time.Second, Periods: 2, Moves: 5, Running: true,
+			}))
+			fx.clock.Advance(12 * time.Second)
+			Expect(t.State()).To(Equal(State{
+				ByoYomi: 15 * time.Second, Periods: 2, Moves: 5, Running: true,
+			}))
+			t.Switch()
+			Expect(t.State()).To(Equal(State{
+				ByoYomi: 15 * time.Second, Periods: 2, Moves: 4, LastMove: 15 * time.Second, Turns: 1,
+			}))
+		})
+		It("should count simple delay after delay", func() {
+			t := fx.timer(Parameters{System: SimpleDelay, Base: 10 * time.Second, Delay: 5 * time.Second})
+			t.Switch()
+			fx.clock.Advance(4 * time.Second)
+			Expect(remaining(t)).To(Equal(10 * time.Second))
+			fx.clock.Advance(3 * time.Second)
+			Expect(remaining(t)).To(Equal(8 * time.Second))
+		})
+	})
+	Context("when written in JSON", func() {
+		It("should have durations in seconds as parameters", func() {
+			s := State{Base: 90 * time.Second, ByoYomi: 2500 * time.Millisecond, Periods: 2, Moves: 5, Running: true,
+				LagUsed: 300 * time.Millisecond}
+			data, err := json.Marshal(s)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(
+				`{"base":90,"byoYomi":"2.5s","periods":2,"moves":5,"running":true,"lagUsed":"300ms"}`))
+			var restored State
+			Expect(json.Unmarshal(data, &restored)).To(Succeed())
+			Expect(restored).To(Equal(s))
+			Expect(json.Unmarshal([]byte(`{"base":"soon"}`), &restored)).ToNot(Succeed())
+		})
+	})
+	Context("when restored", func() {
+		It("should be exactly as snapshot", func() {
+			t := fx.timer(parameters)
+			t.Switch()
+			fx.clock.Advance(12 * time.Second)
+			t.Switch()
+			t.Switch()
+			fx.clock.Advance(4 * time.Second)
+			data, err := json.Marshal(t.State())
+			Expect(err).ToNot(HaveOccurred())
+
+			var s State
+			Expect(json.Unmarshal(data, &s)).To(Succeed())
+			restored := fx.timer(parameters)
+			Expect(restored.Restore(s)).To(Succeed())
+			Expect(restored.State()).To(Equal(t.State()))
+
+			restored = fx.timer(parameters)
+			Expect(restored.Restore(s)).To(Succeed())
+			// 14 seconds of the current period and one more period are left.
+			fx.clock.Advance(33 * time.Second)
+			Expect(fx.over).To(BeFalse())
+			fx.clock.Advance(time.Second)
+			Expect(fx.over).To(BeTrue())
+		})
+		for _, system := range []System{SimpleDelay, Bronstein} {
+			system := system
+			It("should keep delay used by "+system.String()+" move", func() {
+				p := Parameters{System: system, Base: 10 * time.Second, Delay: 3 * time.Second}
+				roundTrip := func(t *Timer) *Timer {
+					data, err := json.Marshal(t.State())
+					Expect(err).ToNot(HaveOccurred())
+					var s State
+					Expect(json.Unmarshal(data, &s)).To(Succeed())
+					restored := fx.timer(p)
+					Expect(restored.Restore(s)).To(Succeed())
+					Expect(restored.State()).To(Equal(t.State()))
+					return restored
+				}
+				t := fx.timer(p)
+				Expect(fx.move(t, 5*time.Second)).To(Equal(8 * time.Second))
+				t.Switch()
+				fx.clock.Advance(2 * time.Second)
+				Expect(t.State().DelayLeft).To(Equal(time.Second))
+
+				running := roundTrip(t)
+				Expect(running.LastMoveTime()).To(Equal(5 * time.Second))
+				Expect(t.Pause()).To(Succeed())
+				paused := roundTrip(t)
+				Expect(paused.Resume()).To(Succeed())
+
+				fx.clock.Advance(2 * time.Second)
+				for _, restored := range []*Timer{running, paused} {
+					restored.Switch()
+					Expect(remaining(restored)).To(Equal(7 * time.Second))
+					Expect(restored.State().Turns).To(Equal(2))
+					Expect(restored.LastMoveTime()).To(Equal(2 * time.Second))
+				}
+			})
+		}
+		It("should reject state not matching parameters", func() {
+			for _, s := range []State{
+				{Base: -time.Second, Periods: 1},
+				{ByoYomi: time.Minute, Periods: 1},
+				{ByoYomi: time.Second, Periods: 3},
+				{ByoYomi: time.Second, Periods: 1, Moves: 6},
+				{Base: time.Second, DelayLeft: time.Second},
+				{Base: time.Second, LastMove: -time.Second},
+				{},
+			} {
+				Expect(fx.timer(parameters).Restore(s)).ToNot(Succeed(), "%+v", s)
+			}
+			t := fx.timer(parameters)
+			t.Switch()
+			Expect(t.Restore(State{Base: time.Second, Periods: 1})).ToNot(Succeed())
+		})
+	})
+})
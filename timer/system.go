@@ -0,0 +1,89 @@
+package timer
+
+import (
+	"fmt"
+)
+
+// System is time control system.
+type System byte
+
+const (
+	// ByoYomi is main time followed by byo-yomi periods. Without byo-yomi
+	// it works as Absolute and moves greater than one with single period
+	// work as Canadian, both are kept for compatibility.
+	ByoYomi System = iota
+	// Fischer is main time with increment added after each move.
+	Fischer
+	// SimpleDelay is main time, which starts to count down each move
+	// after delay.
+	SimpleDelay
+	// Bronstein is main time, which is refunded after each move by time
+	// used, but not more than delay.
+	Bronstein
+	// Canadian is main time followed by blocks of byo-yomi time to play
+	// moves stones each. Player, who doesn't play the stones in time,
+	// loses one of periods and gets a new block.
+	Canadian
+	// Hourglass is main time, time used by player is added to the
+	// opponent. Hourglass timers are created in pair by NewTimerPair.
+	Hourglass
+	// Ing is main time followed by overtime periods, which are not reset
+	// after moves. Each period started costs penalty points in scoring,
+	// player loses when all periods, at most three, run out.
+	Ing
+	// Absolute is main time only, i.e. sudden death.
+	Absolute
+)
+
+func (s System) String() string {
+	switch s {
+	case ByoYomi:
+		return "byo-yomi"
+	case Fischer:
+		return "fischer"
+	case SimpleDelay:
+		return "simple delay"
+	case Bronstein:
+		return "bronstein"
+	case Canadian:
+		return "canadian"
+	case Hourglass:
+		return "hourglass"
+	case Ing:
+		return "ing"
+	case Absolute:
+		return "absolute"
+	}
+	return fmt.Sprintf("System(%d)", byte(s))
+}
+
+func (s System) MarshalText() ([]byte, error) {
+	if s > Absolute {
+		return nil, fmt.Errorf("unknown time system %d", byte(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *System) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "byo-yomi":
+		*s = ByoYomi
+	case "fischer":
+		*s = Fischer
+	case "simple delay":
+		*s = SimpleDelay
+	case "bronstein":
+		*s = Bronstein
+	case "canadian":
+		*s = Canadian
+	case "hourglass":
+		*s = Hourglass
+	case "ing":
+		*s = Ing
+	case "absolute":
+		*s = Absolute
+	default:
+		return fmt.Errorf("unknown time system %q", text)
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package timer
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -12,22 +15,210 @@ const (
 	period
 )
 
+// Parameters of time control. Fields used depend on the system.
+// Durations are written to JSON as seconds when they are whole, e.g.
+// 600, and as duration strings otherwise, e.g. "2.5s".
 type Parameters struct {
-	Base    int `json:"base"`
-	ByoYomi int `json:"byoYomi"`
-	Periods int `json:"periods"`
-	Moves   int `json:"moves"`
+	System  System
+	Base    time.Duration
+	ByoYomi time.Duration
+	Periods int
+	Moves   int
+	// Increment is added to main time after each move in Fischer system.
+	Increment time.Duration
+	// Cap is the most main time can grow to by increments, zero is no
+	// cap.
+	Cap time.Duration
+	// Delay is time of each move not taken from main time in simple delay
+	// and Bronstein systems.
+	Delay time.Duration
+	// Lag is allowance of network lag, nil is no lag compensation.
+	Lag *Lag
+	// MoveLimit is the most time of each move regardless of time left
+	// in any system, player loses when the move takes longer. Zero is
+	// no limit.
+	MoveLimit time.Duration
+}
+
+// Validate checks that parameters are valid for their system.
+func (p Parameters) Validate() error {
+	if p.Base < 0 {
+		return errors.New("base should be greater or equal to zero")
+	}
+	if p.ByoYomi < 0 {
+		return errors.New("byo-yomi should be greater or equal to zero")
+	}
+	if p.System != Fischer && (p.Increment != 0 || p.Cap != 0) {
+		return errors.New("increment and cap are only for fischer system")
+	}
+	if p.System != SimpleDelay && p.System != Bronstein && p.Delay != 0 {
+		return errors.New("delay is only for delay systems")
+	}
+	if p.Lag != nil {
+		if err := p.Lag.validate(); err != nil {
+			return err
+		}
+	}
+	if p.MoveLimit < 0 {
+		return errors.New("move limit should be greater or equal to zero")
+	}
+	switch p.System {
+	case ByoYomi:
+		return p.validateByoYomi()
+	case Fischer:
+		return p.validateFischer()
+	case SimpleDelay, Bronstein:
+		return p.validateDelay()
+	case Canadian:
+		return p.validateCanadian()
+	case Hourglass:
+		return p.validateHourglass()
+	case Ing:
+		return p.validateIng()
+	case Absolute:
+		return p.validateAbsolute()
+	}
+	return fmt.Errorf("unknown time system %v", p.System)
+}
+
+func (p Parameters) validateByoYomi() error {
+	if p.Base == 0 && p.ByoYomi == 0 {
+		return errors.New("both base and byo-yomi duration can't be zero")
+	}
+	if p.ByoYomi == 0 {
+		if p.Periods != 0 {
+			return errors.New("periods should be zero")
+		}
+		if p.Moves != 0 {
+			return errors.New("moves should be zero")
+		}
+		return nil
+	}
+	if p.Periods < 1 {
+		return errors.New("periods should be greater than zero")
+	}
+	if p.Moves < 1 {
+		return errors.New("moves should be greater than one")
+	}
+	if p.Periods > 1 && p.Moves > 1 {
+		return errors.New("both periods and moves can't be greater than one")
+	}
+	return nil
+}
+
+func (p Parameters) validateFischer() error {
+	if p.ByoYomi != 0 || p.Periods != 0 || p.Moves != 0 {
+		return errors.New("fischer system has no byo-yomi")
+	}
+	if p.Base <= 0 {
+		return errors.New("base should be greater than zero")
+	}
+	if p.Increment <= 0 {
+		return errors.New("increment should be greater than zero")
+	}
+	if p.Cap < 0 {
+		return errors.New("cap should be greater or equal to zero")
+	}
+	if p.Cap != 0 && p.Cap < p.Base {
+		return errors.New("cap should be greater or equal to base")
+	}
+	return nil
+}
+
+func (p Parameters) validateCanadian() error {
+	if p.ByoYomi <= 0 {
+		return errors.New("byo-yomi should be greater than zero")
+	}
+	if p.Moves < 1 {
+		return errors.New("moves should be greater than zero")
+	}
+	if p.Periods < 1 {
+		return errors.New("periods should be greater than zero")
+	}
+	return nil
+}
+
+func (p Parameters) validateHourglass() error {
+	if p.ByoYomi != 0 || p.Periods != 0 || p.Moves != 0 {
+		return errors.New("hourglass system has no byo-yomi")
+	}
+	if p.Base <= 0 {
+		return errors.New("base should be greater than zero")
+	}
+	return nil
+}
+
+func (p Parameters) validateAbsolute() error {
+	if p.ByoYomi != 0 || p.Periods != 0 || p.Moves != 0 {
+		return errors.New("absolute system has no byo-yomi")
+	}
+	if p.Base <= 0 {
+		return errors.New("base should be greater than zero")
+	}
+	return nil
+}
+
+// hasOvertime reports whether byo-yomi follows main time.
+func (p Parameters) hasOvertime() bool {
+	switch p.System {
+	case ByoYomi:
+		return p.ByoYomi > 0
+	case Canadian, Ing:
+		return true
+	}
+	return false
+}
+
+// maxIngPeriods is overtime periods count of Ing rules.
+const maxIngPeriods = 3
+
+func (p Parameters) validateIng() error {
+	if p.Base <= 0 {
+		return errors.New("base should be greater than zero")
+	}
+	if p.ByoYomi <= 0 {
+		return errors.New("byo-yomi should be greater than zero")
+	}
+	if p.Periods < 1 || p.Periods > maxIngPeriods {
+		return fmt.Errorf("ing system should have from 1 to %d periods", maxIngPeriods)
+	}
+	if p.Moves != 0 {
+		return errors.New("ing system has no moves per period")
+	}
+	return nil
+}
+
+func (p Parameters) validateDelay() error {
+	if p.ByoYomi != 0 || p.Periods != 0 || p.Moves != 0 {
+		return fmt.Errorf("%v system has no byo-yomi", p.System)
+	}
+	if p.Base <= 0 {
+		return errors.New("base should be greater than zero")
+	}
+	if p.Delay <= 0 {
+		return errors.New("delay should be greater than zero")
+	}
+	return nil
 }
 
 type Callbacks struct {
 	OnBaseOver   func()
 	OnPeriodOver func()
 	OnOver       func()
+	// OnCountdown is called when remaining main time or time of byo-yomi
+	// period reaches one of Countdown thresholds, e.g. 30s, 20s, 10s and
+	// every second after. Thresholds passed before the timer starts are
+	// skipped.
+	OnCountdown func(remaining time.Duration)
+	Countdown   []time.Duration
 }
 
+// Timer is safe for concurrent use.
 type Timer struct {
+	mu         sync.Mutex
 	parameters Parameters
 	callbacks  Callbacks
+	clock      Clock
 
 	base    time.Duration
 	byoYomi time.Duration
@@ -40,52 +231,97 @@ type Timer struct {
 
 	startedAt time.Time
 
-	timer *time.Timer
+	timer      Alarm
+	generation int
+	// countdown is alarm of the next countdown threshold, thresholds
+	// are sorted from the longest.
+	countdown  Alarm
+	thresholds []time.Duration
+
+	// partner is linked timer of the opponent in hourglass system.
+	partner *Timer
+
+	paused        bool
+	pausedElapsed time.Duration
+	pausedAt      time.Time
+
+	// moveStartedAt is start of the running move shifted by pauses and
+	// lag credit, lastMove is time of the last finished move.
+	moveStartedAt time.Time
+	lastMove      time.Duration
+	// limit is alarm of move limit, limitGeneration tells it from alarms
+	// stopped or replaced, e.g. by pause and resume.
+	limit           Alarm
+	limitGeneration int
+
+	// turns is count of moves timed.
+	turns      int
+	lagUsed    time.Duration
+	lagCredits []LagCredit
 }
 
 func NewTimer(parameters Parameters, callbacks Callbacks) (*Timer, error) {
+	return NewTimerWithClock(parameters, callbacks, RealClock)
+}
 
-	if parameters.Base < 0 {
-		return nil, errors.New("base should be greater or equal to zero")
+// NewTimerWithClock returns timer measuring time by clock.
+func NewTimerWithClock(parameters Parameters, callbacks Callbacks, clock Clock) (*Timer, error) {
+	if parameters.System == Hourglass {
+		return nil, errors.New("hourglass timers should be created in pair")
 	}
+	return newTimer(parameters, callbacks, clock)
+}
 
-	if parameters.ByoYomi < 0 {
-		return nil, errors.New("byo-yomi should be greater or equal to zero")
-	}
+// NewTimerPair returns timers of two players. Timers of hourglass system
+// are linked, time used by one player is added to the other one, timers
+// of other systems are independent.
+func NewTimerPair(parameters Parameters, first Callbacks, second Callbacks, clock Clock) (*Timer, *Timer, error) {
+	return NewAsymmetricTimerPair(parameters, parameters, first, second, clock)
+}
 
-	if parameters.Base == 0 && parameters.ByoYomi == 0 {
-		return nil, errors.New("both base and byo-yomi duration can't be zero")
+// NewAsymmetricTimerPair returns timers of two players with different
+// parameters, e.g. time odds. Hourglass system should be used by both.
+func NewAsymmetricTimerPair(firstParameters Parameters, secondParameters Parameters, first Callbacks, second Callbacks,
+	clock Clock) (*Timer, *Timer, error) {
+	if (firstParameters.System == Hourglass) != (secondParameters.System == Hourglass) {
+		return nil, nil, errors.New("hourglass system should be used by both timers")
+	}
+	a, err := newTimer(firstParameters, first, clock)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := newTimer(secondParameters, second, clock)
+	if err != nil {
+		return nil, nil, err
+	}
+	if firstParameters.System == Hourglass {
+		a.partner, b.partner = b, a
 	}
+	return a, b, nil
+}
 
-	if parameters.ByoYomi == 0 {
-		if parameters.Periods != 0 {
-			return nil, errors.New("periods should be zero")
-		}
-		if parameters.Moves != 0 {
-			return nil, errors.New("moves should be zero")
-		}
-	} else {
-		if parameters.Periods < 1 {
-			return nil, errors.New("periods should be greater than zero")
-		}
-		if parameters.Moves < 1 {
-			return nil, errors.New("moves should be greater than one")
-		}
-		if parameters.Periods > 1 && parameters.Moves > 1 {
-			return nil, errors.New("both periods and moves can't be greater than one")
-		}
+func newTimer(parameters Parameters, callbacks Callbacks, clock Clock) (*Timer, error) {
+	if err := parameters.Validate(); err != nil {
+		return nil, err
 	}
 
 	t := &Timer{
 		parameters: parameters,
 		callbacks:  callbacks,
-		base:       time.Duration(parameters.Base) * time.Second,
-		byoYomi:    time.Duration(parameters.ByoYomi) * time.Second,
+		clock:      clock,
+		base:       parameters.Base,
+		byoYomi:    parameters.ByoYomi,
 		periods:    parameters.Periods,
 		moves:      parameters.Moves,
 		over:       false,
 		timer:      nil,
 	}
+	for _, r := range callbacks.Countdown {
+		if r > 0 {
+			t.thresholds = append(t.thresholds, r)
+		}
+	}
+	sort.Slice(t.thresholds, func(i, j int) bool { return t.thresholds[i] > t.thresholds[j] })
 
 	if parameters.Base > 0 {
 		t.mode = base
@@ -96,85 +332,345 @@ func NewTimer(parameters Parameters, callbacks Callbacks) (*Timer, error) {
 	return t, nil
 }
 
+// Switch starts stopped timer or stops running one. Switch and timer
+// expiration may happen at the same instant on different goroutines,
+// the one that comes first wins: expiration after switch is ignored,
+// time left after late switch is zero. Switch of paused timer is
+// ignored.
 func (t *Timer) Switch() {
-	if t.over {
+	t.toggle(0, false)
+}
+
+// toggle switches timer, think time is reported by client or not.
+func (t *Timer) toggle(think time.Duration, reported bool) {
+	t.mu.Lock()
+	if t.over || t.paused {
+		t.mu.Unlock()
 		return
 	}
+	var used time.Duration
 	if t.timer == nil {
-		t.switchOn()
+		t.moveStartedAt = t.clock.Now()
+		t.switchOn(0)
+		t.startLimit()
 	} else {
-		t.switchOff()
+		t.stopLimit()
+		t.turns++
+		if reported {
+			t.creditLag(think)
+		}
+		t.lastMove = t.clock.Now().Sub(t.moveStartedAt)
+		used = t.switchOff()
+	}
+	t.mu.Unlock()
+	// Partner is credited after unlock, so partners switching at once
+	// don't wait for each other.
+	if t.partner != nil && used > 0 {
+		t.partner.credit(used)
 	}
 }
 
-func (t *Timer) switchOn() {
+// credit adds d to main time, running timer is restarted with the new
+// time.
+func (t *Timer) credit(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.over {
+		return
+	}
+	if t.timer == nil {
+		t.base += d
+		return
+	}
+	t.stopAlarms()
+	t.base = t.elapse(t.base, 0) + d
+	t.startBaseTimer(0)
+}
+
+// Pause stops running timer without ending the move. Resume continues
+// the move counting time used before pause, e.g. delay.
+func (t *Timer) Pause() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.paused {
+		return errors.New("timer is already paused")
+	}
+	if t.timer == nil {
+		return errors.New("timer isn't running")
+	}
+	t.stopAlarms()
+	t.stopLimit()
+	t.timer = nil
+	t.pausedElapsed = t.clock.Now().Sub(t.startedAt)
+	t.pausedAt = t.clock.Now()
+	t.paused = true
+	return nil
+}
+
+func (t *Timer) Resume() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.paused {
+		return errors.New("timer isn't paused")
+	}
+	t.paused = false
+	t.moveStartedAt = t.moveStartedAt.Add(t.clock.Now().Sub(t.pausedAt))
+	t.switchOn(t.pausedElapsed)
+	t.startLimit()
+	return nil
+}
+
+// switchOn starts timer of the move, which already took elapsed time.
+func (t *Timer) switchOn(elapsed time.Duration) {
 	if t.mode == base {
-		t.startBaseTimer()
+		t.startBaseTimer(elapsed)
 	} else {
-		t.startPeriodTimer()
+		t.startPeriodTimer(elapsed)
+	}
+}
+
+func (t *Timer) startBaseTimer(elapsed time.Duration) {
+	t.generation++
+	generation := t.generation
+	d := t.base
+	if t.parameters.System == SimpleDelay {
+		d += t.parameters.Delay
+	}
+	t.startedAt = t.clock.Now().Add(-elapsed)
+	t.timer = t.clock.AfterFunc(d-elapsed, func() { t.onBaseOver(generation) })
+	t.scheduleCountdown(generation, d)
+}
+
+func (t *Timer) startPeriodTimer(elapsed time.Duration) {
+	t.generation++
+	generation := t.generation
+	t.startedAt = t.clock.Now().Add(-elapsed)
+	t.timer = t.clock.AfterFunc(t.byoYomi-elapsed, func() { t.onPeriodOver(generation) })
+	t.scheduleCountdown(generation, t.byoYomi)
+}
+
+// scheduleCountdown sets alarm of the next threshold of running alarm
+// of generation, which fires end after start. Threshold alarms are set
+// from start, not from previous alarm, so they don't drift.
+func (t *Timer) scheduleCountdown(generation int, end time.Duration) {
+	t.countdown = nil
+	if t.callbacks.OnCountdown == nil {
+		return
+	}
+	elapsed := t.clock.Now().Sub(t.startedAt)
+	for _, r := range t.thresholds {
+		if at := end - r; at > elapsed {
+			t.countdown = t.clock.AfterFunc(at-elapsed, func() { t.onCountdown(generation, end, r) })
+			return
+		}
+	}
+}
+
+func (t *Timer) onCountdown(generation int, end time.Duration, remaining time.Duration) {
+	t.mu.Lock()
+	if !t.current(generation) {
+		t.mu.Unlock()
+		return
+	}
+	t.scheduleCountdown(generation, end)
+	t.mu.Unlock()
+	t.callbacks.OnCountdown(remaining)
+}
+
+// startLimit sets alarm of move limit of the running move.
+func (t *Timer) startLimit() {
+	if t.parameters.MoveLimit == 0 {
+		return
+	}
+	t.limitGeneration++
+	generation := t.limitGeneration
+	d := t.parameters.MoveLimit - t.clock.Now().Sub(t.moveStartedAt)
+	t.limit = t.clock.AfterFunc(d, func() { t.onLimitOver(generation) })
+}
+
+func (t *Timer) stopLimit() {
+	if t.limit != nil {
+		t.limit.Stop()
+		t.limit = nil
 	}
-	t.startedAt = time.Now()
 }
 
-func (t *Timer) startBaseTimer() {
-	t.timer = time.AfterFunc(t.base, t.onBaseOver)
+// onLimitOver ends timer when alarm of generation is still the current
+// one of the running move.
+func (t *Timer) onLimitOver(generation int) {
+	t.mu.Lock()
+	if t.limit == nil || t.limitGeneration != generation || t.timer == nil {
+		t.mu.Unlock()
+		return
+	}
+	t.stopAlarms()
+	t.limit = nil
+	t.timer = nil
+	t.over = true
+	t.mu.Unlock()
+	if t.callbacks.OnOver != nil {
+		t.callbacks.OnOver()
+	}
 }
 
-func (t *Timer) startPeriodTimer() {
-	t.timer = time.AfterFunc(t.byoYomi, t.onPeriodOver)
+func (t *Timer) stopAlarms() {
+	t.timer.Stop()
+	if t.countdown != nil {
+		t.countdown.Stop()
+		t.countdown = nil
+	}
 }
 
-func (t *Timer) switchOff() {
+// switchOff returns main time used by the move.
+func (t *Timer) switchOff() time.Duration {
+	var used time.Duration
 	if t.mode == base {
+		before := t.base
 		t.stopBaseTimer()
+		used = before - t.base
+	} else if t.parameters.System == Ing {
+		// Ing period goes on until it runs out.
+		t.stopPeriodTimer()
 	} else {
 		t.stopPeriodTimer()
 		t.moves--
 		if t.moves == 0 {
 			t.moves = t.parameters.Moves
-			t.byoYomi = time.Duration(t.parameters.ByoYomi) * time.Second
+			t.byoYomi = t.parameters.ByoYomi
 		}
 	}
 	t.timer = nil
+	return used
 }
 
 func (t *Timer) stopBaseTimer() {
-	t.timer.Stop()
-	t.base = t.base - time.Now().Sub(t.startedAt)
+	t.stopAlarms()
+	switch t.parameters.System {
+	case SimpleDelay, Bronstein:
+		// Simple delay doesn't count time of delay and Bronstein refunds
+		// it, so both take from main time only time used over delay.
+		t.base = t.elapse(t.base, t.parameters.Delay)
+	case Fischer:
+		t.base = t.elapse(t.base, 0) + t.parameters.Increment
+		if limit := t.parameters.Cap; limit > 0 && t.base > limit {
+			t.base = limit
+		}
+	default:
+		t.base = t.elapse(t.base, 0)
+	}
 }
 
 func (t *Timer) stopPeriodTimer() {
-	t.timer.Stop()
-	t.byoYomi = t.byoYomi - time.Now().Sub(t.startedAt)
+	t.stopAlarms()
+	t.byoYomi = t.elapse(t.byoYomi, 0)
+}
+
+func (t *Timer) hasDelay() bool {
+	return t.parameters.System == SimpleDelay || t.parameters.System == Bronstein
+}
+
+// delayUsed returns delay used by the running or paused move.
+func (t *Timer) delayUsed() time.Duration {
+	used := t.clock.Now().Sub(t.startedAt)
+	if t.paused {
+		used = t.pausedElapsed
+	}
+	if used > t.parameters.Delay {
+		return t.parameters.Delay
+	}
+	return used
 }
 
-func (t *Timer) onBaseOver() {
-	if t.parameters.ByoYomi == 0 {
+// elapse returns time left of d since start not counting free time,
+// which is not negative.
+func (t *Timer) elapse(d time.Duration, free time.Duration) time.Duration {
+	used := t.clock.Now().Sub(t.startedAt) - free
+	if t.paused {
+		used = t.pausedElapsed - free
+	}
+	if used < 0 {
+		used = 0
+	}
+	d -= used
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// current reports whether alarm of generation is not stopped or
+// replaced, alarm may fire while switch waits for the lock.
+func (t *Timer) current(generation int) bool {
+	return t.timer != nil && t.generation == generation
+}
+
+// onBaseOver and onPeriodOver call callbacks after unlock, so callbacks
+// may call timer.
+func (t *Timer) onBaseOver(generation int) {
+	t.mu.Lock()
+	if !t.current(generation) {
+		t.mu.Unlock()
+		return
+	}
+	var callback func()
+	if !t.parameters.hasOvertime() {
+		t.base = 0
 		t.over = true
-		if t.callbacks.OnOver != nil {
-			t.callbacks.OnOver()
-		}
+		t.timer = nil
+		t.stopLimit()
+		callback = t.callbacks.OnOver
 	} else {
+		t.base = 0
 		t.mode = period
-		t.startPeriodTimer()
-		if t.callbacks.OnBaseOver != nil {
-			t.callbacks.OnBaseOver()
-		}
+		t.startPeriodTimer(0)
+		callback = t.callbacks.OnBaseOver
+	}
+	t.mu.Unlock()
+	if callback != nil {
+		callback()
 	}
 }
 
-func (t *Timer) onPeriodOver() {
+func (t *Timer) onPeriodOver(generation int) {
+	t.mu.Lock()
+	if !t.current(generation) {
+		t.mu.Unlock()
+		return
+	}
+	var callback func()
 	t.periods--
 	if t.periods == 0 {
 		t.over = true
-		if t.callbacks.OnOver != nil {
-			t.callbacks.OnOver()
-		}
+		t.timer = nil
+		t.stopLimit()
+		callback = t.callbacks.OnOver
 	} else {
-		t.byoYomi = time.Duration(t.parameters.ByoYomi) * time.Second
-		t.startPeriodTimer()
-		if t.callbacks.OnPeriodOver != nil {
-			t.callbacks.OnPeriodOver()
-		}
+		// New period is a new block of stones.
+		t.byoYomi = t.parameters.ByoYomi
+		t.moves = t.parameters.Moves
+		t.startPeriodTimer(0)
+		callback = t.callbacks.OnPeriodOver
+	}
+	t.mu.Unlock()
+	if callback != nil {
+		callback()
+	}
+}
+
+// LastMoveTime returns time taken by the last finished move, not
+// counting pauses and credited lag.
+func (t *Timer) LastMoveTime() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastMove
+}
+
+// Remaining returns main time left, or time of the current byo-yomi
+// period when main time is over, and periods left.
+func (t *Timer) Remaining() (time.Duration, int) {
+	s := t.State()
+	if s.Base > 0 || s.Over {
+		return s.Base, s.Periods
 	}
+	return s.ByoYomi, s.Periods
 }
@@ -1,8 +1,11 @@
 package timer_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/someanon/ggo/timer"
 
 	"testing"
 )
@@ -11,3 +14,73 @@ func TestTimer(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Timer Suite")
 }
+
+// fixture runs timers of a spec on fake clock and records what their
+// callbacks reported. Each Describe creates its own in BeforeEach.
+type fixture struct {
+	clock       *FakeClock
+	periodsOver int
+	baseOver    bool
+	over        bool
+	countdowns  []countdown
+}
+
+// countdown is OnCountdown call, at is clock time since the start.
+type countdown struct {
+	at        time.Duration
+	remaining time.Duration
+}
+
+var start = time.Unix(0, 0)
+
+func newFixture() *fixture {
+	return &fixture{clock: NewFakeClock(start)}
+}
+
+// callbacks record calls into fixture, thresholds are countdown ones.
+func (f *fixture) callbacks(thresholds ...time.Duration) Callbacks {
+	return Callbacks{
+		OnPeriodOver: func() { f.periodsOver++ },
+		OnBaseOver:   func() { f.baseOver = true },
+		OnOver:       func() { f.over = true },
+		OnCountdown: func(remaining time.Duration) {
+			f.countdowns = append(f.countdowns, countdown{at: f.clock.Now().Sub(start), remaining: remaining})
+		},
+		Countdown: thresholds,
+	}
+}
+
+// timer creates timer on the fixture clock with recording callbacks.
+func (f *fixture) timer(p Parameters, thresholds ...time.Duration) *Timer {
+	t, err := NewTimerWithClock(p, f.callbacks(thresholds...), f.clock)
+	Expect(err).ToNot(HaveOccurred())
+	return t
+}
+
+// move runs timer for a move taking d and returns remaining time.
+func (f *fixture) move(t *Timer, d time.Duration) time.Duration {
+	t.Switch()
+	f.clock.Advance(d)
+	t.Switch()
+	return remaining(t)
+}
+
+func remaining(t *Timer) time.Duration {
+	remaining, _ := t.Remaining()
+	return remaining
+}
+
+// expectValidation checks that both Validate and NewTimer accept valid
+// parameters and reject invalid ones.
+func expectValidation(valid []Parameters, invalid []Parameters) {
+	for _, p := range valid {
+		Expect(p.Validate()).To(Succeed(), "%+v", p)
+		_, err := NewTimer(p, Callbacks{})
+		Expect(err).ToNot(HaveOccurred(), "%+v", p)
+	}
+	for _, p := range invalid {
+		Expect(p.Validate()).ToNot(Succeed(), "%+v", p)
+		_, err := NewTimer(p, Callbacks{})
+		Expect(err).To(HaveOccurred(), "%+v", p)
+	}
+}
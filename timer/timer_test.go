@@ -1,6 +1,8 @@
 package timer_test
 
 import (
+	"runtime"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -13,64 +15,64 @@ var _ = Describe("Timer", func() {
 		var err error
 		Context("when base or byo-yomi are negative", func() {
 			It("should be error", func() {
-				_, err = NewTimer(Parameters{-1, 0, 0, 0}, Callbacks{})
+				_, err = NewTimer(Parameters{Base: -1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
-				_, err = NewTimer(Parameters{1, -1, 1, 1}, Callbacks{})
+				_, err = NewTimer(Parameters{Base: 1 * time.Second, ByoYomi: -1, Periods: 1, Moves: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
 			})
 		})
 		Context("when both base and byo-yomi are zero", func() {
 			It("should be error", func() {
-				_, err = NewTimer(Parameters{0, 0, 1, 1}, Callbacks{})
+				_, err = NewTimer(Parameters{Periods: 1, Moves: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
 			})
 		})
 		Context("when not zero period or moves when zero byo-yomi", func() {
 			It("should be error", func() {
-				_, err = NewTimer(Parameters{1, 0, 1, 0}, Callbacks{})
+				_, err = NewTimer(Parameters{Base: 1 * time.Second, Periods: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
-				_, err = NewTimer(Parameters{1, 0, 0, 1}, Callbacks{})
+				_, err = NewTimer(Parameters{Base: 1 * time.Second, Moves: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
 			})
 		})
 		Context("when zero or negative period when byo-yomi greater than zero", func() {
 			It("should be error", func() {
-				_, err = NewTimer(Parameters{0, 1, 0, 1}, Callbacks{})
+				_, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Moves: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
-				_, err = NewTimer(Parameters{0, 1, -1, 1}, Callbacks{})
+				_, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: -1, Moves: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
 			})
 		})
 		Context("when zero or negative moves when byo-yomi greater than zero", func() {
 			It("should be error", func() {
-				_, err = NewTimer(Parameters{0, 1, 1, 0}, Callbacks{})
+				_, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: 1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
-				_, err = NewTimer(Parameters{0, 1, 1, -1}, Callbacks{})
+				_, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: 1, Moves: -1}, Callbacks{})
 				Expect(err).To(HaveOccurred())
 			})
 		})
 		Context("when both period and moves are greater than one", func() {
 			It("should be error", func() {
-				_, err = NewTimer(Parameters{0, 1, 2, 2}, Callbacks{})
+				_, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: 2, Moves: 2}, Callbacks{})
 				Expect(err).To(HaveOccurred())
 			})
 		})
 		Context("when valid parameters", func() {
 			var t *Timer
 			It("should succeed", func() {
-				t, err = NewTimer(Parameters{1, 0, 0, 0}, Callbacks{})
+				t, err = NewTimer(Parameters{Base: 1 * time.Second}, Callbacks{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(t).ToNot(BeNil())
-				t, err = NewTimer(Parameters{0, 1, 1, 1}, Callbacks{})
+				t, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: 1, Moves: 1}, Callbacks{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(t).ToNot(BeNil())
-				t, err = NewTimer(Parameters{1, 1, 1, 1}, Callbacks{})
+				t, err = NewTimer(Parameters{Base: 1 * time.Second, ByoYomi: 1 * time.Second, Periods: 1, Moves: 1}, Callbacks{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(t).ToNot(BeNil())
-				t, err = NewTimer(Parameters{0, 1, 10, 1}, Callbacks{})
+				t, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: 10, Moves: 1}, Callbacks{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(t).ToNot(BeNil())
-				t, err = NewTimer(Parameters{0, 1, 1, 10}, Callbacks{})
+				t, err = NewTimer(Parameters{ByoYomi: 1 * time.Second, Periods: 1, Moves: 10}, Callbacks{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(t).ToNot(BeNil())
 			})
@@ -78,6 +80,7 @@ var _ = Describe("Timer", func() {
 	})
 	Describe("running", func() {
 		var (
+			clock      *FakeClock
 			periodOver bool
 			baseOver   bool
 			over       bool
@@ -87,8 +90,14 @@ var _ = Describe("Timer", func() {
 			baseOver = false
 			over = false
 		}
+		// createTimer takes durations in seconds.
 		createTimer := func(base, byoYomi, periods, moves int) *Timer {
-			t, err := NewTimer(Parameters{base, byoYomi, periods, moves}, Callbacks{
+			t, err := NewTimerWithClock(Parameters{
+				Base:    time.Duration(base) * time.Second,
+				ByoYomi: time.Duration(byoYomi) * time.Second,
+				Periods: periods,
+				Moves:   moves,
+			}, Callbacks{
 				OnPeriodOver: func() {
 					periodOver = true
 				},
@@ -98,32 +107,33 @@ var _ = Describe("Timer", func() {
 				OnOver: func() {
 					over = true
 				},
-			})
+			}, clock)
 			Expect(err).ToNot(HaveOccurred())
 			return t
 		}
 		BeforeEach(func() {
+			clock = NewFakeClock(time.Unix(0, 0))
 			reset()
 		})
 		Context("only base time", func() {
 			It("works correct", func() {
 				t := createTimer(1, 0, 0, 0)
 				t.Switch()
-				time.Sleep(500 * time.Millisecond)
+				clock.Advance(500 * time.Millisecond)
 				t.Switch()
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
-				time.Sleep(600 * time.Millisecond)
+				clock.Advance(600 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
 				t.Switch()
-				time.Sleep(499 * time.Millisecond)
+				clock.Advance(499 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
-				time.Sleep(1 * time.Millisecond)
+				clock.Advance(1 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeTrue())
@@ -133,49 +143,61 @@ var _ = Describe("Timer", func() {
 			It("works correct", func() {
 				t := createTimer(1, 1, 1, 1)
 				t.Switch()
-				time.Sleep(1001 * time.Millisecond)
+				clock.Advance(1000 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeTrue())
 				Expect(over).To(BeFalse())
 				reset()
-				time.Sleep(998 * time.Millisecond)
+				clock.Advance(999 * time.Millisecond)
 				t.Switch()
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
 				t.Switch()
-				time.Sleep(999 * time.Millisecond)
+				clock.Advance(999 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
 				reset()
-				time.Sleep(2 * time.Millisecond)
+				clock.Advance(1 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeTrue())
 			})
+			It("goes from base time to byo-yomi within one advance", func() {
+				t := createTimer(1, 1, 1, 1)
+				t.Switch()
+				clock.Advance(2 * time.Second)
+				Expect(baseOver).To(BeTrue())
+				Expect(over).To(BeTrue())
+			})
 		})
 		Context("only multiple byo-yomi periods", func() {
 			It("works correct", func() {
 				t := createTimer(0, 1, 2, 1)
 				t.Switch()
-				time.Sleep(1001 * time.Millisecond)
+				clock.Advance(1000 * time.Millisecond)
 				Expect(periodOver).To(BeTrue())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
+				remaining, periods := t.Remaining()
+				Expect(remaining).To(Equal(time.Second))
+				Expect(periods).To(Equal(1))
+				clock.Advance(1000 * time.Millisecond)
+				Expect(over).To(BeTrue())
 			})
 		})
 		Context("only canadian byo-yomi", func() {
 			It("overs correct", func() {
 				t := createTimer(0, 1, 1, 2)
 				t.Switch()
-				time.Sleep(500 * time.Millisecond)
+				clock.Advance(500 * time.Millisecond)
 				t.Switch()
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
 				t.Switch()
-				time.Sleep(501 * time.Millisecond)
+				clock.Advance(500 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeTrue())
@@ -183,7 +205,7 @@ var _ = Describe("Timer", func() {
 			It("works correct", func() {
 				t := createTimer(0, 1, 1, 2)
 				t.Switch()
-				time.Sleep(500 * time.Millisecond)
+				clock.Advance(500 * time.Millisecond)
 				t.Switch()
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
@@ -191,11 +213,113 @@ var _ = Describe("Timer", func() {
 				t.Switch()
 				t.Switch()
 				t.Switch()
-				time.Sleep(999 * time.Millisecond)
+				clock.Advance(999 * time.Millisecond)
 				Expect(periodOver).To(BeFalse())
 				Expect(baseOver).To(BeFalse())
 				Expect(over).To(BeFalse())
 			})
 		})
 	})
+	Describe("concurrent use", func() {
+		It("ignores expiration coming after switch", func() {
+			clock := &lateClock{FakeClock: NewFakeClock(time.Unix(0, 0))}
+			over := false
+			t, err := NewTimerWithClock(Parameters{Base: 1 * time.Second}, Callbacks{
+				OnOver: func() { over = true },
+			}, clock)
+			Expect(err).ToNot(HaveOccurred())
+			t.Switch()
+			clock.Advance(time.Second)
+			// Switch gets the lock before the fired alarm.
+			t.Switch()
+			clock.fire()
+			Expect(over).To(BeFalse())
+			remaining, _ := t.Remaining()
+			Expect(remaining).To(Equal(time.Duration(0)))
+		})
+		It("is safe for switching and expiring at once", func() {
+			// Run with -race to check synchronization.
+			var (
+				mu    sync.Mutex
+				calls int
+			)
+			over := make(chan struct{})
+			clock := NewFakeClock(time.Unix(0, 0))
+			t, err := NewTimerWithClock(Parameters{ByoYomi: time.Millisecond, Periods: 5, Moves: 1}, Callbacks{
+				OnPeriodOver: func() {
+					mu.Lock()
+					calls++
+					mu.Unlock()
+				},
+				OnOver: func() {
+					mu.Lock()
+					calls++
+					mu.Unlock()
+					close(over)
+				},
+			}, clock)
+			Expect(err).ToNot(HaveOccurred())
+			expired := func() bool {
+				select {
+				case <-over:
+					return true
+				default:
+					return false
+				}
+			}
+			// Clock is advanced concurrently with switching, so periods
+			// run out between switches.
+			go func() {
+				for !expired() {
+					clock.Advance(time.Millisecond)
+					runtime.Gosched()
+				}
+			}()
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 1000 && !expired(); j++ {
+						t.Switch()
+						t.Remaining()
+					}
+				}()
+			}
+			wg.Wait()
+			// Timer left stopped by switching runs out alone.
+			if !t.State().Running {
+				t.Switch()
+			}
+			Eventually(over).Should(BeClosed())
+			_, periods := t.Remaining()
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(periods).To(Equal(0))
+			Expect(periods + calls).To(Equal(5))
+		})
+	})
 })
+
+// lateClock is FakeClock, which alarms can't be stopped after they are
+// due, as if they are already fired and wait for the timer lock.
+type lateClock struct {
+	*FakeClock
+	due []func()
+}
+
+func (c *lateClock) AfterFunc(d time.Duration, f func()) Alarm {
+	at := c.Now().Add(d)
+	return c.FakeClock.AfterFunc(d, func() {
+		if !c.Now().Before(at) {
+			c.due = append(c.due, f)
+		}
+	})
+}
+
+func (c *lateClock) fire() {
+	for _, f := range c.due {
+		f()
+	}
+	c.due = nil
+}
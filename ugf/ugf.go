@@ -0,0 +1,229 @@
+// Package ugf reads UGF and UGI game records of Pandanet (IGS).
+//
+// Record is INI-like text with [Header] and [Data] sections. Data lines
+// are "QD,B1,1,0": column letter from the left and row letter from the
+// bottom, color with move number, then unused fields. Move number 0
+// marks handicap stones, places outside the board are passes. Header
+// Time is "<base>,<byo-yomi>,<stones>" in seconds, i.e. Canadian
+// byo-yomi of IGS, stones may be omitted for a single stone.
+package ugf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/sgf"
+	"github.com/someanon/ggo/timer"
+)
+
+// Read reads game record and replays it.
+func Read(r io.Reader) (*ggo.Game, error) {
+	header := make(map[string]string)
+	var data []string
+
+	section := ""
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line[1 : len(line)-1])
+			continue
+		}
+		switch section {
+		case "header":
+			if i := strings.IndexByte(line, '='); i > 0 {
+				header[strings.ToLower(line[:i])] = strings.TrimSpace(line[i+1:])
+			}
+		case "data":
+			data = append(data, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	parameters := ggo.Parameters{BoardSize: 19}
+	if v, ok := header["size"]; ok {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > 26 {
+			return nil, fmt.Errorf("ugf: invalid board size %q", v)
+		}
+		parameters.BoardSize = size
+	}
+	if v, ok := header["hdcp"]; ok {
+		fields := strings.Split(v, ",")
+		if len(fields) > 1 {
+			komi, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("ugf: invalid komi %q", fields[1])
+			}
+			parameters.Komi = komi
+		}
+	}
+	if v, ok := header["time"]; ok {
+		ts, err := timeSystem(v)
+		if err != nil {
+			return nil, err
+		}
+		parameters.TimeSystem = ts
+	}
+
+	g := ggo.NewGame(parameters)
+	blackName, blackRank := player(header["playerb"])
+	whiteName, whiteRank := player(header["playerw"])
+	g.SetInfo(ggo.Info{
+		BlackName: blackName,
+		BlackRank: blackRank,
+		WhiteName: whiteName,
+		WhiteRank: whiteRank,
+		Date:      date(header["date"]),
+		Event:     header["title"],
+		Place:     header["place"],
+		Result:    result(header["winner"]),
+	})
+
+	size := parameters.BoardSize
+	for i, line := range data {
+		fields := strings.Split(line, ",")
+		if len(fields) < 2 || len(fields[0]) != 2 || len(fields[1]) < 2 {
+			return nil, fmt.Errorf("ugf: invalid data line %q", line)
+		}
+		var color ggo.Color
+		switch fields[1][0] {
+		case 'B':
+			color = ggo.Black
+		case 'W':
+			color = ggo.White
+		default:
+			return nil, fmt.Errorf("ugf: invalid color in line %q", line)
+		}
+		number, err := strconv.Atoi(fields[1][1:])
+		if err != nil {
+			return nil, fmt.Errorf("ugf: invalid move number in line %q", line)
+		}
+		column := int(fields[0][0]) - 'A'
+		row := size - 1 - (int(fields[0][1]) - 'A')
+		onBoard := row >= 0 && column >= 0 && row < size && column < size
+
+		if number == 0 {
+			if !onBoard {
+				return nil, fmt.Errorf("ugf: handicap stone out of board in line %q", line)
+			}
+			if err := g.Setup(row, column, color); err != nil {
+				return nil, fmt.Errorf("ugf: invalid handicap stone in line %q: %v", line, err)
+			}
+			continue
+		}
+		if len(g.Moves()) == 0 && g.MoveColor() != color {
+			if err := g.SetMoveColor(color); err != nil {
+				return nil, fmt.Errorf("ugf: %v", err)
+			}
+		}
+		if onBoard {
+			err = g.Move(row, column, color)
+		} else {
+			err = g.Pass(color)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ugf: data line %d: %v", i+1, err)
+		}
+	}
+	return g, nil
+}
+
+// ToSGF converts game record to SGF.
+func ToSGF(r io.Reader, w io.Writer) error {
+	return sgf.Convert(Read, r, w)
+}
+
+// timeSystem converts "600,300,25" value, zero base and byo-yomi is no
+// time limit.
+func timeSystem(v string) (*timer.Parameters, error) {
+	fields := strings.Split(v, ",")
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("ugf: invalid time %q", v)
+	}
+	values := []int{0, 0, 1}
+	for i, f := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("ugf: invalid time %q", v)
+		}
+		values[i] = n
+	}
+	base, byoYomi, stones := values[0], values[1], values[2]
+	var p *timer.Parameters
+	switch {
+	case base == 0 && byoYomi == 0:
+		return nil, nil
+	case byoYomi == 0:
+		p = &timer.Parameters{System: timer.Absolute, Base: time.Duration(base) * time.Second}
+	case stones > 1:
+		p = &timer.Parameters{
+			System:  timer.Canadian,
+			Base:    time.Duration(base) * time.Second,
+			ByoYomi: time.Duration(byoYomi) * time.Second,
+			Periods: 1,
+			Moves:   stones,
+		}
+	default:
+		p = &timer.Parameters{
+			Base:    time.Duration(base) * time.Second,
+			ByoYomi: time.Duration(byoYomi) * time.Second,
+			Periods: 1,
+			Moves:   1,
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("ugf: invalid time %q: %v", v, err)
+	}
+	return p, nil
+}
+
+// player splits "name,rank,..." value.
+func player(v string) (string, string) {
+	fields := strings.Split(v, ",")
+	name := strings.TrimSpace(fields[0])
+	rank := ""
+	if len(fields) > 1 {
+		rank = strings.TrimSpace(fields[1])
+	}
+	return name, rank
+}
+
+// date converts "2005/02/06,10:17" to "2005-02-06".
+func date(v string) string {
+	v = strings.Split(v, ",")[0]
+	return strings.Replace(strings.TrimSpace(v), "/", "-", -1)
+}
+
+// result converts "B,2.5" or "W,C" to SGF form. C means resignation.
+func result(v string) string {
+	fields := strings.Split(v, ",")
+	if len(fields) < 2 {
+		return ""
+	}
+	winner := strings.TrimSpace(fields[0])
+	if winner != "B" && winner != "W" {
+		return ""
+	}
+	how := strings.ToUpper(strings.TrimSpace(fields[1]))
+	switch how {
+	case "C", "R":
+		return winner + "+R"
+	case "T":
+		return winner + "+T"
+	}
+	if _, err := strconv.ParseFloat(how, 64); err == nil {
+		return winner + "+" + how
+	}
+	return winner + "+"
+}
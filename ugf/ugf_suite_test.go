@@ -0,0 +1,13 @@
+package ugf_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestUgf(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ugf Suite")
+}
@@ -0,0 +1,93 @@
+package ugf_test
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/someanon/ggo"
+	"github.com/someanon/ggo/sgf"
+	"github.com/someanon/ggo/timer"
+	. "github.com/someanon/ggo/ugf"
+)
+
+const record = `[Header]
+Lang=JP
+Date=2005/02/06,10:17
+Hdcp=2,0.5
+Size=9
+Time=600,300,25
+PlayerB=black,3k,
+PlayerW=white,2d,
+Winner=W,C
+Title=Friendly
+[Data]
+CC,B0,0,0
+GG,B0,0,0
+EE,W1,1,0
+YA,B2,1,0
+FE,W3,1,0
+[Figure]
+`
+
+var _ = Describe("UGF", func() {
+	It("reads game", func() {
+		g, err := Read(strings.NewReader(record))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.Size()).To(Equal(9))
+		Expect(g.Parameters().Komi).To(Equal(0.5))
+		Expect(g.Info()).To(Equal(ggo.Info{
+			BlackName: "black",
+			BlackRank: "3k",
+			WhiteName: "white",
+			WhiteRank: "2d",
+			Date:      "2005-02-06",
+			Event:     "Friendly",
+			Result:    "W+R",
+		}))
+		Expect(g.SetupStones()).To(Equal([]ggo.Move{
+			{Color: ggo.Black, Row: 6, Column: 2},
+			{Color: ggo.Black, Row: 2, Column: 6},
+		}))
+		Expect(g.Moves()).To(Equal([]ggo.Move{
+			{Color: ggo.White, Row: 4, Column: 4},
+			{Color: ggo.Black, Pass: true},
+			{Color: ggo.White, Row: 4, Column: 5},
+		}))
+	})
+	It("reads time system", func() {
+		g, err := Read(strings.NewReader(record))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.Parameters().TimeSystem).To(Equal(&timer.Parameters{
+			System: timer.Canadian, Base: 10 * time.Minute, ByoYomi: 5 * time.Minute, Periods: 1, Moves: 25,
+		}))
+		for v, ts := range map[string]*timer.Parameters{
+			"600,30":   {Base: 10 * time.Minute, ByoYomi: 30 * time.Second, Periods: 1, Moves: 1},
+			"600,0,25": {System: timer.Absolute, Base: 10 * time.Minute},
+			"0,0":      nil,
+		} {
+			g, err := Read(strings.NewReader("[Header]\nTime=" + v + "\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.Parameters().TimeSystem).To(Equal(ts), v)
+		}
+		for _, v := range []string{"600", "600,-30", "ten,30"} {
+			_, err := Read(strings.NewReader("[Header]\nTime=" + v + "\n"))
+			Expect(err).To(HaveOccurred(), v)
+		}
+	})
+	It("converts game to SGF", func() {
+		var buf bytes.Buffer
+		Expect(ToSGF(strings.NewReader(record), &buf)).To(Succeed())
+		trees, err := sgf.Parse(&buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(trees[0].Values("RE")).To(Equal([]string{"W+R"}))
+		Expect(trees[0].Values("OT")).To(Equal([]string{"25/300 Canadian"}))
+		Expect(trees[0].Values("AB")).To(Equal([]string{"cg", "gc"}))
+	})
+	It("rejects invalid data", func() {
+		_, err := Read(strings.NewReader("[Header]\nSize=9\n[Data]\nEE,X1,1,0\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})